@@ -41,18 +41,111 @@ type CheckConfig struct {
 	// +required
 	Timeout metav1.Duration `json:"timeout"`
 
-	// Protocol is the protocol to use (http, https, tcp, grpc)
-	// +kubebuilder:validation:Enum=http;https;tcp;grpc
+	// Protocol is the protocol to use (http, https, tcp, grpc, udp, icmp, replicas).
+	// icmp checks need CAP_NET_RAW unless the node allows unprivileged ping.
+	// replicas checks read a workload's status from the API, with a URL of the form
+	// replicas://<deployment|statefulset>/<namespace>/<name>, where namespace
+	// must be the HealthCheck's own.
+	// +kubebuilder:validation:Enum=http;https;tcp;grpc;udp;icmp;replicas
 	// +required
 	Protocol string `json:"protocol"`
+
+	// DisableKeepAlives opens a new connection for every check instead of reusing one
+	// +optional
+	DisableKeepAlives bool `json:"disableKeepAlives,omitempty"`
+
+	// InsecureSkipVerify skips verification of the server certificate for https checks
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// CABundle is PEM-encoded CA certificates used to verify the server certificate
+	// for https checks, e.g. an in-cluster mTLS issuer. The system roots are used when empty.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// ExpectBodyContains marks a 2xx response unhealthy unless its body contains this string
+	// +optional
+	ExpectBodyContains string `json:"expectBodyContains,omitempty"`
+
+	// ExpectJSONPath is a dot-separated path into the JSON response body, e.g. checks.0.status.
+	// A 2xx response is unhealthy unless the value at the path equals ExpectJSONValue.
+	// +optional
+	ExpectJSONPath string `json:"expectJSONPath,omitempty"`
+
+	// ExpectJSONValue is the value expected at ExpectJSONPath. Strings are compared as-is,
+	// other values by their JSON encoding, e.g. true or 1.
+	// +optional
+	ExpectJSONValue string `json:"expectJSONValue,omitempty"`
+
+	// Payload is the datagram sent by udp checks. Any reply within the timeout is healthy.
+	// +optional
+	Payload string `json:"payload,omitempty"`
+
+	// Method is the HTTP method of http(s) checks. Defaults to GET.
+	// +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;PATCH
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// RequestBody is sent by POST, PUT and PATCH http(s) checks. For grpc checks it is
+	// the service name sent to the health service in place of the URL path.
+	// +kubebuilder:validation:MaxLength=65536
+	// +optional
+	RequestBody string `json:"requestBody,omitempty"`
+
+	// ContentType is the Content-Type header sent with RequestBody
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// AuthSecretRef sends credentials from a Secret as the Authorization header of http(s) checks
+	// +optional
+	AuthSecretRef *AuthSecretRef `json:"authSecretRef,omitempty"`
 }
 
-// HealthCheckSpec defines the desired state of HealthCheck
+// AuthSecretRef references a Secret in the namespace of the HealthCheck holding
+// either a bearer token or basic auth credentials
+type AuthSecretRef struct {
+	// Name of the Secret
+	// +required
+	Name string `json:"name"`
+
+	// TokenKey is the key holding a bearer token
+	// +optional
+	TokenKey string `json:"tokenKey,omitempty"`
+
+	// UsernameKey is the key holding the basic auth username
+	// +optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// PasswordKey is the key holding the basic auth password
+	// +optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// ServiceSelector selects services whose checks are discovered from their pod probes
+type ServiceSelector struct {
+	// Namespace to select services from. Defaults to the namespace of the HealthCheck.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// LabelSelector matches the labels of the services to check
+	// +required
+	LabelSelector metav1.LabelSelector `json:"labelSelector"`
+}
+
+// HealthCheckSpec defines the desired state of HealthCheck. At least one of
+// Checks or ServiceSelector must be set.
 type HealthCheckSpec struct {
 	// Checks defines the health check endpoints
-	// +required
+	// +optional
 	// +listType=atomic
-	Checks []CheckConfig `json:"checks"`
+	Checks []CheckConfig `json:"checks,omitempty"`
+
+	// ServiceSelector registers checks for every matching service, derived from
+	// the probes of its pods the same way as automatically discovered services.
+	// They are reported as <namespace>/<service>@<healthcheck namespace>.<healthcheck name>
+	// so they do not replace the automatically discovered target of the service.
+	// +optional
+	ServiceSelector *ServiceSelector `json:"serviceSelector,omitempty"`
 }
 
 // HealthCheckStatus defines the observed state of HealthCheck.
@@ -76,10 +169,39 @@ type HealthCheckStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Status is the result of the most recent check (healthy, unhealthy, unknown)
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// LastCheck is when the most recent check ran
+	// +optional
+	LastCheck *metav1.Time `json:"lastCheck,omitempty"`
+
+	// Uptime is the percentage of recent checks that succeeded
+	// +optional
+	Uptime string `json:"uptime,omitempty"`
+
+	// LastError is the error reported by the most recent check, if it failed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// ObservedGeneration is the spec generation last registered with the health checker
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SelectedServices are the namespace/name keys of the services registered
+	// through the service selector
+	// +optional
+	SelectedServices []string `json:"selectedServices,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Uptime",type=string,JSONPath=`.status.uptime`
+// +kubebuilder:printcolumn:name="Last Check",type=date,JSONPath=`.status.lastCheck`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // HealthCheck is the Schema for the healthchecks API
 type HealthCheck struct {
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kdwils/constellation/internal/hierarchy"
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// runDump implements the dump subcommand, which prints the hierarchy of a
+// running instance for debugging without the UI
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	serverURL := fs.String("server-url", "http://localhost:8080", "Base URL of a running constellation server.")
+	output := fs.String("output", "tree", "Output format, one of tree or json.")
+	timeout := fs.Duration("timeout", 10*time.Second, "Timeout for the request to the server.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != "tree" && *output != "json" {
+		return fmt.Errorf("unsupported output %q, must be tree or json", *output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	data, err := fetchState(ctx, strings.TrimSuffix(*serverURL, "/")+"/state")
+	if err != nil {
+		return err
+	}
+
+	nodes := hierarchy.Build(data)
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(nodes)
+	}
+	return hierarchy.RenderTree(os.Stdout, nodes)
+}
+
+func fetchState(ctx context.Context, url string) ([]*types.ServiceHealthInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GET %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data []*types.ServiceHealthInfo
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding state: %w", err)
+	}
+	return data, nil
+}
@@ -18,8 +18,15 @@ package main
 
 import (
 	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -46,6 +53,9 @@ import (
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is set at build time with -ldflags "-X main.version=..."
+	version = "dev"
 )
 
 func init() {
@@ -58,6 +68,14 @@ func init() {
 
 // nolint:gocyclo
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		if err := runDump(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -67,6 +85,32 @@ func main() {
 	var enableHTTP2 bool
 	var serverPort int
 	var staticDir string
+	var assetMaxAge time.Duration
+	var wsWriteWait, wsPongWait, wsPingPeriod time.Duration
+	var wsReadLimit int64
+	var stateSnapshotPath string
+	var registrationDebounce time.Duration
+	var includeUnknownPods bool
+	var completedPodGrace time.Duration
+	var minCheckInterval, maxCheckInterval time.Duration
+	var clusterDomain string
+	var defaultCheckPath string
+	var adminToken string
+	var allowedOrigins string
+	var alertWebhookURL string
+	var alertThreshold int
+	var transitionWebhookURL string
+	var remoteWriteURL string
+	var transitionEvents bool
+	var checkCoalesceWindow time.Duration
+	var checkJitter float64
+	var notifyDebounce time.Duration
+	var enqueueTimeout time.Duration
+	var historySize int
+	var manualCheckLimit int
+	var checkWorkers int
+	var checkAggregation string
+	var eventLogSize int
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -87,6 +131,64 @@ func main() {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.IntVar(&serverPort, "server-port", 8080, "The port for the constellation server")
 	flag.StringVar(&staticDir, "static-dir", "frontend/dist", "Directory containing static UI files")
+	flag.DurationVar(&assetMaxAge, "asset-max-age", 365*24*time.Hour,
+		"How long browsers may cache content-hashed UI assets. Zero disables caching.")
+	flag.DurationVar(&wsWriteWait, "ws-write-wait", 10*time.Second,
+		"How long a WebSocket write may take before the connection is dropped.")
+	flag.DurationVar(&wsPongWait, "ws-pong-wait", 60*time.Second,
+		"How long a WebSocket client may go without answering a ping before it is dropped.")
+	flag.DurationVar(&wsPingPeriod, "ws-ping-period", 54*time.Second,
+		"How often WebSocket clients are pinged. Must be shorter than --ws-pong-wait.")
+	flag.Int64Var(&wsReadLimit, "ws-read-limit", 512,
+		"Largest message in bytes a WebSocket client may send.")
+	flag.DurationVar(&registrationDebounce, "registration-debounce", 2*time.Second,
+		"How long a service must stop changing before its discovered health checks are registered.")
+	flag.BoolVar(&includeUnknownPods, "include-unknown-pods", false,
+		"Keep probing pods in the Unknown phase, which usually means their node is unreachable.")
+	flag.DurationVar(&completedPodGrace, "completed-pod-grace", 0,
+		"Keep probing Succeeded and Failed pods for this long after they finish. Zero excludes them immediately.")
+	flag.DurationVar(&minCheckInterval, "min-check-interval", controller.DefaultMinInterval,
+		"Lower bound for intervals derived from probe periods. Zero leaves it unbounded.")
+	flag.DurationVar(&maxCheckInterval, "max-check-interval", controller.DefaultMaxInterval,
+		"Upper bound for intervals derived from probe periods. Zero leaves it unbounded.")
+	flag.StringVar(&defaultCheckPath, "default-check-path", "",
+		"Path checked with HTTP GET on the first TCP port of services whose pods declare no probes. Disabled when empty.")
+	flag.StringVar(&clusterDomain, "cluster-domain", controller.DefaultClusterDomain,
+		"DNS suffix of in-cluster service hostnames used in check URLs.")
+	flag.StringVar(&adminToken, "admin-token", os.Getenv("CONSTELLATION_ADMIN_TOKEN"),
+		"Bearer token required by the admin API. The admin API is disabled when empty.")
+	flag.StringVar(&allowedOrigins, "allowed-origins", "",
+		"Comma-separated origins allowed to open a WebSocket, e.g. https://app.example.com,*.example.com. "+
+			"Only same-origin connections are allowed when empty.")
+	flag.StringVar(&alertWebhookURL, "alert-webhook-url", "",
+		"Webhook URL notified when a service stays unhealthy. Alerting is disabled when empty.")
+	flag.IntVar(&alertThreshold, "alert-threshold", 3,
+		"Number of consecutive unhealthy checks before an alert is sent.")
+	flag.StringVar(&remoteWriteURL, "remote-write-url", "",
+		"Prometheus remote-write endpoint that every check result is pushed to. Disabled when empty.")
+	flag.StringVar(&transitionWebhookURL, "transition-webhook-url", "",
+		"Webhook URL notified whenever a service changes health status. Disabled when empty.")
+	flag.BoolVar(&transitionEvents, "transition-events", false,
+		"Record a Kubernetes Event on the Service whenever it changes health status.")
+	flag.DurationVar(&checkCoalesceWindow, "check-coalesce-window", 0,
+		"Share one request between checks of the same URL that fire within this window. Disabled when zero.")
+	flag.IntVar(&checkWorkers, "check-workers", 64,
+		"Maximum number of health checks run at once. Zero or less runs every check on its own goroutine.")
+	flag.StringVar(&checkAggregation, "check-aggregation", string(healthcheck.AggregateWorstOf),
+		"How the checks of a service combine into its status: worst-of fails on any failing check, "+
+			"all-of only when every check fails.")
+	flag.Float64Var(&checkJitter, "check-jitter", 0,
+		"Fraction of the check interval (0-1) used to randomly stagger checks. Disabled when zero.")
+	flag.DurationVar(&notifyDebounce, "notify-debounce", 200*time.Millisecond,
+		"Coalesce health updates pushed to UI clients into at most one per window. Zero sends every change.")
+	flag.DurationVar(&enqueueTimeout, "enqueue-timeout", 5*time.Second,
+		"How long a reconcile waits for room on a full registration queue before the event is dropped.")
+	flag.IntVar(&historySize, "history-size", 100, "Number of check results kept per service.")
+	flag.IntVar(&eventLogSize, "event-log-size", 500, "Number of target changes kept for /events/log.")
+	flag.IntVar(&manualCheckLimit, "manual-check-limit", 6,
+		"Manual check triggers allowed per service per minute. Zero removes the limit.")
+	flag.StringVar(&stateSnapshotPath, "state-snapshot-path", "",
+		"If set, health state is saved to this file on shutdown and restored from it on startup.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -95,6 +197,12 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	aggregation, err := healthcheck.ParseAggregation(checkAggregation)
+	if err != nil {
+		setupLog.Error(err, "invalid --check-aggregation")
+		os.Exit(1)
+	}
+
 	disableHTTP2 := func(c *tls.Config) {
 		setupLog.Info("disabling http/2")
 		c.NextProtos = []string{"http/1.1"}
@@ -160,17 +268,57 @@ func main() {
 		os.Exit(1)
 	}
 
-	healthChecker := healthcheck.NewHealthChecker()
+	var notifiers []healthcheck.Notifier
+	if transitionWebhookURL != "" {
+		notifiers = append(notifiers, healthcheck.NewWebhookNotifier(transitionWebhookURL, http.DefaultClient))
+	}
+	if transitionEvents {
+		notifiers = append(notifiers, healthcheck.NewEventNotifier(mgr.GetEventRecorderFor("constellation")))
+	}
 
-	serviceReconciler := controller.NewServiceReconciler(mgr, healthChecker)
-	if err = serviceReconciler.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Service")
-		os.Exit(1)
+	var exporters []healthcheck.Exporter
+	var remoteWrite *healthcheck.RemoteWriteExporter
+	if remoteWriteURL != "" {
+		remoteWrite = healthcheck.NewRemoteWriteExporter(remoteWriteURL, http.DefaultClient)
+		exporters = append(exporters, remoteWrite)
 	}
 
-	podReconciler := controller.NewPodReconciler(mgr, healthChecker)
-	if err = podReconciler.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Pod")
+	healthChecker := healthcheck.NewHealthChecker(
+		healthcheck.WithAlertConfig(healthcheck.AlertConfig{
+			WebhookURL: alertWebhookURL,
+			Threshold:  alertThreshold,
+		}),
+		healthcheck.WithCheckCoalescing(checkCoalesceWindow),
+		healthcheck.WithJitter(checkJitter),
+		healthcheck.WithWorkers(checkWorkers),
+		healthcheck.WithAggregation(aggregation),
+		healthcheck.WithNotifyDebounce(notifyDebounce),
+		healthcheck.WithEnqueueTimeout(enqueueTimeout),
+		healthcheck.WithHistorySize(historySize),
+		healthcheck.WithManualCheckLimit(manualCheckLimit),
+		healthcheck.WithEventLogSize(eventLogSize),
+		healthcheck.WithNotifiers(notifiers...),
+		healthcheck.WithExporters(exporters...),
+		healthcheck.WithWorkloadReader(mgr.GetClient()),
+	)
+
+	if stateSnapshotPath != "" {
+		if err := loadStateSnapshot(stateSnapshotPath, healthChecker); err != nil {
+			setupLog.Error(err, "unable to restore state snapshot, starting with empty state", "path", stateSnapshotPath)
+		}
+	}
+
+	discoveryOpts := []controller.DiscoveryOpt{
+		controller.WithRegistrationDebounce(registrationDebounce),
+		controller.WithIncludeUnknownPods(includeUnknownPods),
+		controller.WithCompletedPodGrace(completedPodGrace),
+		controller.WithIntervalBounds(minCheckInterval, maxCheckInterval),
+		controller.WithClusterDomain(clusterDomain),
+		controller.WithDefaultCheckPath(defaultCheckPath),
+	}
+	serviceReconciler := controller.NewServiceReconciler(mgr, healthChecker, discoveryOpts...)
+	if err = serviceReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Service")
 		os.Exit(1)
 	}
 
@@ -178,6 +326,8 @@ func main() {
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		HealthChecker: healthChecker,
+		Discovery:     discoveryOpts,
+		APIReader:     mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HealthCheck")
 		os.Exit(1)
@@ -197,8 +347,22 @@ func main() {
 
 	// Start state manager immediately so it can process updates
 	go healthChecker.Start(ctx)
+	if remoteWrite != nil {
+		go remoteWrite.Run(ctx)
+	}
 
-	srv := server.NewServer(healthChecker, staticDir, serverPort)
+	var cacheSynced atomic.Bool
+	srv := server.NewServer(healthChecker, staticDir, serverPort,
+		server.WithReadiness("cache-synced", cacheSynced.Load),
+		server.WithReadiness("health-checker", healthChecker.Running),
+		server.WithAdminToken(adminToken),
+		server.WithAssetMaxAge(assetMaxAge),
+		server.WithVersion(version),
+		server.WithWebSocketTimeouts(wsWriteWait, wsPongWait, wsPingPeriod),
+		server.WithWebSocketReadLimit(wsReadLimit),
+		server.WithAllowedOrigins(strings.Split(allowedOrigins, ",")),
+		server.WithLogger(ctrl.Log.WithName("server")),
+	)
 	go func() {
 		setupLog.Info("starting constellation server", "port", serverPort, "static-dir", staticDir)
 		if err := srv.Serve(ctx); err != nil {
@@ -222,7 +386,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	cacheSynced.Store(true)
 	setupLog.Info("initial cluster state built successfully")
 
 	<-ctx.Done()
+
+	if stateSnapshotPath != "" {
+		if err := saveStateSnapshot(stateSnapshotPath, healthChecker); err != nil {
+			setupLog.Error(err, "unable to save state snapshot", "path", stateSnapshotPath)
+		}
+	}
+}
+
+func loadStateSnapshot(path string, healthChecker *healthcheck.HealthChecker) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := healthChecker.LoadSnapshot(f); err != nil {
+		return err
+	}
+	setupLog.Info("restored state snapshot", "path", path)
+	return nil
+}
+
+// saveStateSnapshot writes to a temporary file first so a crash mid-write
+// never leaves a truncated snapshot behind.
+func saveStateSnapshot(path string, healthChecker *healthcheck.HealthChecker) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := healthChecker.SaveSnapshot(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
@@ -1,4 +1,4 @@
-import type { ServiceHealthInfo, ServiceCardData } from '../types'
+import type { ServiceHealthInfo, ServiceCardData, HealthCheckEntry } from '../types'
 
 export function transformToServiceCards(healthData: ServiceHealthInfo[]): ServiceCardData[] {
   return healthData.map(health => ({
@@ -8,13 +8,23 @@ export function transformToServiceCards(healthData: ServiceHealthInfo[]): Servic
     lastUpdate: health.last_check,
     latency: calculateAverageLatency(health.history),
     url: health.url,
+    warnings: health.warnings || [],
     serviceHealth: {
       status: health.status,
-      healthCheckHistory: health.history || []
+      healthCheckHistory: expandHistory(health)
     }
   }))
 }
 
+// The backend only sets url/method on entries that differ from the service's own
+function expandHistory(health: ServiceHealthInfo): HealthCheckEntry[] {
+  return (health.history || []).map(entry => ({
+    ...entry,
+    url: entry.url || health.url,
+    method: entry.method || health.method
+  }))
+}
+
 function calculateAverageLatency(history: Array<{ latency: number }>): number {
   if (!history || history.length === 0) {
     return 0
@@ -1,23 +1,44 @@
-export type HealthStatus = 'healthy' | 'unhealthy' | 'unknown'
+export type HealthStatus = 'healthy' | 'unhealthy' | 'unknown' | 'maintenance'
 
 export interface HealthCheckEntry {
   timestamp: string
   status: HealthStatus
   latency: number
   error?: string
-  url: string
-  method: string
+  url?: string
+  method?: string
   response_code?: number
 }
 
+export interface CheckHealth {
+  name: string
+  url?: string
+  status: HealthStatus
+  last_check: string
+  latency: number
+  error?: string
+}
+
 export interface ServiceHealthInfo {
   service_name: string
   namespace: string
   last_check: string
   status: HealthStatus
+  last_transition_time: string
   uptime: number
+  avg_latency: number
+  p50_latency: number
+  p95_latency: number
+  p99_latency: number
+  current_streak: number
+  streak_status?: HealthStatus
   history: HealthCheckEntry[]
   url: string
+  method: string
+  group?: string
+  warnings?: string[]
+  external_name?: string
+  checks?: CheckHealth[]
 }
 
 export interface HierarchyNode {
@@ -26,9 +47,23 @@ export interface HierarchyNode {
   namespace?: string
   relatives?: HierarchyNode[]
   hostnames?: string[]
+  external_name?: string
   health_info?: ServiceHealthInfo
 }
 
+export interface HierarchyOp {
+  op: 'upsert' | 'delete'
+  path: string[]
+  node?: HierarchyNode
+}
+
+export interface HierarchyMessage {
+  type: 'snapshot' | 'ops'
+  seq: number
+  nodes?: HierarchyNode[]
+  ops?: HierarchyOp[]
+}
+
 export interface ServiceHealthData {
   status: HealthStatus
   healthCheckHistory: HealthCheckEntry[]
@@ -41,5 +76,6 @@ export interface ServiceCardData {
   lastUpdate: string
   latency: number
   url: string
+  warnings: string[]
   serviceHealth: ServiceHealthData
 }
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
 )
 
@@ -8,6 +9,11 @@ import (
 type Cache[T any] struct {
 	entries map[string]T
 	mu      sync.RWMutex
+
+	// capacity bounds the number of entries; zero means unbounded
+	capacity int
+	order    *list.List
+	elements map[string]*list.Element
 }
 
 // New creates a new cache
@@ -17,18 +23,50 @@ func New[T any]() *Cache[T] {
 	}
 }
 
+// NewWithCapacity creates a cache holding at most capacity entries, evicting
+// the least recently used entry when full. A capacity of zero or less is unbounded.
+func NewWithCapacity[T any](capacity int) *Cache[T] {
+	c := New[T]()
+	if capacity <= 0 {
+		return c
+	}
+
+	c.capacity = capacity
+	c.order = list.New()
+	c.elements = make(map[string]*list.Element)
+	return c
+}
+
 // Set adds or updates an entry in the cache
 func (c *Cache[T]) Set(key string, value T) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.entries[key] = value
+
+	if c.capacity == 0 {
+		return
+	}
+	c.touch(key)
+	for len(c.entries) > c.capacity {
+		c.remove(c.order.Back().Value.(string))
+	}
 }
 
 // Get retrieves an entry from the cache
 func (c *Cache[T]) Get(key string) (T, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	if c.capacity == 0 {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		value, exists := c.entries[key]
+		return value, exists
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	value, exists := c.entries[key]
+	if exists {
+		c.touch(key)
+	}
 	return value, exists
 }
 
@@ -36,7 +74,27 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 func (c *Cache[T]) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+// touch marks key as the most recently used entry
+func (c *Cache[T]) touch(key string) {
+	if element, exists := c.elements[key]; exists {
+		c.order.MoveToFront(element)
+		return
+	}
+	c.elements[key] = c.order.PushFront(key)
+}
+
+func (c *Cache[T]) remove(key string) {
 	delete(c.entries, key)
+	if c.capacity == 0 {
+		return
+	}
+	if element, exists := c.elements[key]; exists {
+		c.order.Remove(element)
+		delete(c.elements, key)
+	}
 }
 
 // Size returns the number of entries in the cache
@@ -69,3 +127,15 @@ func (c *Cache[T]) List() []T {
 	}
 	return values
 }
+
+// Snapshot returns a copy of all entries taken under a single lock
+func (c *Cache[T]) Snapshot() map[string]T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshot := make(map[string]T, len(c.entries))
+	for key, value := range c.entries {
+		snapshot[key] = value
+	}
+	return snapshot
+}
@@ -1,6 +1,8 @@
 package cache_test
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/kdwils/constellation/internal/cache"
@@ -218,3 +220,150 @@ func TestCache_List(t *testing.T) {
 		})
 	}
 }
+
+func TestCache_Snapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+	}{
+		{
+			name:    "empty cache",
+			entries: map[string]string{},
+		},
+		{
+			name: "cache with entries",
+			entries: map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New[string]()
+			for key, value := range tt.entries {
+				c.Set(key, value)
+			}
+			got := c.Snapshot()
+			if len(got) != len(tt.entries) {
+				t.Errorf("TestCache_Snapshot() length = %v, want %v", len(got), len(tt.entries))
+			}
+			for key, value := range tt.entries {
+				if got[key] != value {
+					t.Errorf("TestCache_Snapshot() value for %s = %v, want %v", key, got[key], value)
+				}
+			}
+			got["extra"] = "value"
+			if _, ok := c.Get("extra"); ok {
+				t.Errorf("TestCache_Snapshot() mutating snapshot modified the cache")
+			}
+		})
+	}
+}
+
+func TestCache_SnapshotConcurrent(t *testing.T) {
+	c := cache.New[int]()
+	var wg sync.WaitGroup
+
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			for j := range 100 {
+				c.Set(key, j)
+				c.Delete(key)
+			}
+		}()
+	}
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				for key, value := range c.Snapshot() {
+					if key == "" || value < 0 {
+						t.Errorf("TestCache_SnapshotConcurrent() unexpected entry %s=%d", key, value)
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestCache_NewWithCapacity(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		sets     []string
+		gets     []string
+		wantKeys []string
+	}{
+		{
+			name:     "evicts least recently set",
+			capacity: 2,
+			sets:     []string{"a", "b", "c"},
+			wantKeys: []string{"b", "c"},
+		},
+		{
+			name:     "get refreshes recency",
+			capacity: 2,
+			sets:     []string{"a", "b"},
+			gets:     []string{"a"},
+			wantKeys: []string{"a", "c"},
+		},
+		{
+			name:     "updating an existing key refreshes recency",
+			capacity: 2,
+			sets:     []string{"a", "b", "a"},
+			wantKeys: []string{"a", "c"},
+		},
+		{
+			name:     "zero capacity is unbounded",
+			capacity: 0,
+			sets:     []string{"a", "b"},
+			wantKeys: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewWithCapacity[string](tt.capacity)
+			for _, key := range tt.sets {
+				c.Set(key, key)
+			}
+			for _, key := range tt.gets {
+				c.Get(key)
+			}
+			c.Set("c", "c")
+
+			if c.Size() != len(tt.wantKeys) {
+				t.Errorf("TestCache_NewWithCapacity() size = %v, want %v", c.Size(), len(tt.wantKeys))
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("TestCache_NewWithCapacity() missing key %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestCache_NewWithCapacityDelete(t *testing.T) {
+	c := cache.NewWithCapacity[string](2)
+	c.Set("a", "a")
+	c.Set("b", "b")
+	c.Delete("a")
+	c.Set("c", "c")
+
+	for _, key := range []string{"b", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("TestCache_NewWithCapacityDelete() missing key %s", key)
+		}
+	}
+	if c.Size() != 2 {
+		t.Errorf("TestCache_NewWithCapacityDelete() size = %v, want 2", c.Size())
+	}
+}
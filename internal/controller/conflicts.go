@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// portClaims returns the node ports and external ip:port pairs a service
+// exposes outside the cluster, formatted for use in warnings
+func portClaims(service corev1.Service) []string {
+	var ips []string
+	ips = append(ips, service.Spec.ExternalIPs...)
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			ips = append(ips, ingress.IP)
+		}
+	}
+
+	var claims []string
+	for _, port := range service.Spec.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		if port.NodePort != 0 {
+			claims = append(claims, fmt.Sprintf("node port %d/%s", port.NodePort, protocol))
+		}
+		for _, ip := range ips {
+			claims = append(claims, fmt.Sprintf("%s/%s", net.JoinHostPort(ip, strconv.Itoa(int(port.Port))), protocol))
+		}
+	}
+
+	slices.Sort(claims)
+	return slices.Compact(claims)
+}
+
+// findPortConflicts reports every claim of service that another service in
+// services also holds
+func findPortConflicts(service corev1.Service, services []corev1.Service) []string {
+	claims := portClaims(service)
+	if len(claims) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	for _, other := range services {
+		if other.Namespace == service.Namespace && other.Name == service.Name {
+			continue
+		}
+		otherClaims := portClaims(other)
+		for _, claim := range claims {
+			if slices.Contains(otherClaims, claim) {
+				warnings = append(warnings, fmt.Sprintf("%s is also claimed by service %s/%s",
+					claim, other.Namespace, other.Name))
+			}
+		}
+	}
+	return warnings
+}
+
+// serviceToConflictingServices enqueues every other service that claims
+// ports when a service changes, so warnings appear and clear on both sides.
+// Only the new object is seen here, so a service the change moved off of
+// cannot be told apart from the rest.
+func serviceToConflictingServices(c client.Reader) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			return nil
+		}
+
+		var services corev1.ServiceList
+		if err := c.List(ctx, &services); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, other := range services.Items {
+			if other.Namespace == service.Namespace && other.Name == service.Name {
+				continue
+			}
+			if len(portClaims(other)) == 0 {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: other.Namespace, Name: other.Name},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newNodePortService(name, namespace string, nodePort int32) corev1.Service {
+	service := newTestService(name, namespace, map[string]string{"app": name},
+		corev1.ServicePort{Port: 80, NodePort: nodePort, TargetPort: intstr.FromInt32(8080)})
+	service.Spec.Type = corev1.ServiceTypeNodePort
+	return service
+}
+
+func newLoadBalancerService(name, namespace, ip string, port int32) corev1.Service {
+	service := newTestService(name, namespace, map[string]string{"app": name},
+		corev1.ServicePort{Port: port, TargetPort: intstr.FromInt32(8080)})
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ip}}
+	return service
+}
+
+func TestFindPortConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  corev1.Service
+		services []corev1.Service
+		want     []string
+	}{
+		{
+			name:    "shared node port",
+			service: newNodePortService("api", "default", 30080),
+			services: []corev1.Service{
+				newNodePortService("api", "default", 30080),
+				newNodePortService("web", "shop", 30080),
+			},
+			want: []string{"node port 30080/TCP is also claimed by service shop/web"},
+		},
+		{
+			name:    "distinct node ports",
+			service: newNodePortService("api", "default", 30080),
+			services: []corev1.Service{
+				newNodePortService("web", "shop", 30081),
+			},
+		},
+		{
+			name:    "shared load balancer ip and port",
+			service: newLoadBalancerService("api", "default", "203.0.113.10", 443),
+			services: []corev1.Service{
+				newLoadBalancerService("web", "default", "203.0.113.10", 443),
+			},
+			want: []string{"203.0.113.10:443/TCP is also claimed by service default/web"},
+		},
+		{
+			name:    "shared load balancer ip on different ports",
+			service: newLoadBalancerService("api", "default", "203.0.113.10", 443),
+			services: []corev1.Service{
+				newLoadBalancerService("web", "default", "203.0.113.10", 80),
+			},
+		},
+		{
+			name:    "cluster ip services never conflict",
+			service: newTestService("api", "default", nil, corev1.ServicePort{Port: 80}),
+			services: []corev1.Service{
+				newTestService("web", "default", nil, corev1.ServicePort{Port: 80}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findPortConflicts(tt.service, tt.services)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findPortConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceReconciler_ReportsPortConflicts(t *testing.T) {
+	api := newNodePortService("api", "default", 30080)
+	web := newNodePortService("web", "shop", 30080)
+
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        fake.NewClientBuilder().WithObjects(&api, &web).Build(),
+		HealthChecker: registry,
+		registrations: newDebouncer(0),
+	}
+
+	tests := []struct {
+		name string
+		req  types.NamespacedName
+		want []string
+	}{
+		{
+			name: "first service",
+			req:  types.NamespacedName{Namespace: "default", Name: "api"},
+			want: []string{"node port 30080/TCP is also claimed by service shop/web"},
+		},
+		{
+			name: "second service",
+			req:  types.NamespacedName{Namespace: "shop", Name: "web"},
+			want: []string{"node port 30080/TCP is also claimed by service default/api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: tt.req}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			got := registry.targetMetadata(tt.req.String()).Warnings
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reconcile() warnings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceToConflictingServices(t *testing.T) {
+	api := newNodePortService("api", "default", 30080)
+	web := newNodePortService("web", "shop", 30080)
+	other := newNodePortService("other", "default", 30081)
+	internal := newTestService("internal", "default", map[string]string{"app": "internal"},
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	moved := newTestService("api", "default", map[string]string{"app": "api"},
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+
+	c := fake.NewClientBuilder().WithObjects(&api, &web, &other, &internal).Build()
+
+	tests := []struct {
+		name    string
+		service corev1.Service
+		want    []reconcile.Request
+	}{
+		{
+			name:    "claimants are enqueued",
+			service: api,
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "other"}},
+				{NamespacedName: types.NamespacedName{Namespace: "shop", Name: "web"}},
+			},
+		},
+		{
+			name:    "service moved off its claims still clears the other side",
+			service: moved,
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "other"}},
+				{NamespacedName: types.NamespacedName{Namespace: "shop", Name: "web"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serviceToConflictingServices(c)(context.Background(), &tt.service)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serviceToConflictingServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	healthv1alpha1 "github.com/kdwils/constellation/api/v1alpha1"
+	"github.com/kdwils/constellation/internal/healthcheck"
+)
+
+// Secrets are only watched by metadata, which still needs list and watch, and
+// are read one at a time through an uncached reader
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// secretReader is where referenced Secrets are read from
+func (r *HealthCheckReconciler) secretReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// resolveAuthorization reads the Secret referenced by each check and sets the
+// resulting Authorization header on the matching converted check. Errors name
+// the Secret and key but never their contents.
+func resolveAuthorization(
+	ctx context.Context,
+	c client.Reader,
+	namespace string,
+	apiChecks []healthv1alpha1.CheckConfig,
+	checks []healthcheck.CheckConfig,
+) error {
+	for i, apiCheck := range apiChecks {
+		ref := apiCheck.AuthSecretRef
+		if ref == nil {
+			continue
+		}
+
+		var secret corev1.Secret
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+			return fmt.Errorf("check %q: getting secret %q: %w", apiCheck.Name, ref.Name, err)
+		}
+		authorization, err := authorizationFromSecret(*ref, secret)
+		if err != nil {
+			return fmt.Errorf("check %q: %w", apiCheck.Name, err)
+		}
+		checks[i].Authorization = authorization
+	}
+	return nil
+}
+
+func authorizationFromSecret(ref healthv1alpha1.AuthSecretRef, secret corev1.Secret) (string, error) {
+	basic := ref.UsernameKey != "" || ref.PasswordKey != ""
+	switch {
+	case ref.TokenKey != "" && basic:
+		return "", errors.New("authSecretRef must set tokenKey or usernameKey and passwordKey, not both")
+	case ref.TokenKey != "":
+		token, err := secretValue(secret, ref.TokenKey)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + strings.TrimSpace(token), nil
+	case ref.UsernameKey != "" && ref.PasswordKey != "":
+		username, err := secretValue(secret, ref.UsernameKey)
+		if err != nil {
+			return "", err
+		}
+		password, err := secretValue(secret, ref.PasswordKey)
+		if err != nil {
+			return "", err
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	default:
+		return "", errors.New("authSecretRef must set tokenKey or both usernameKey and passwordKey")
+	}
+}
+
+func secretValue(secret corev1.Secret, key string) (string, error) {
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", secret.Name, key)
+	}
+	return string(value), nil
+}
+
+// credentialsFingerprint hashes the credentials of checks so a rotated Secret
+// can be detected without keeping the credentials themselves around
+func credentialsFingerprint(checks []healthcheck.CheckConfig) string {
+	hash := sha256.New()
+	found := false
+	for _, check := range checks {
+		if check.Authorization != "" {
+			found = true
+		}
+		hash.Write([]byte(check.Authorization))
+		hash.Write([]byte{0})
+	}
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// credentialsChanged reports whether fingerprint differs from the credentials
+// last registered for key
+func (r *HealthCheckReconciler) credentialsChanged(key, fingerprint string) bool {
+	r.credentialsMu.Lock()
+	defer r.credentialsMu.Unlock()
+	return r.credentials[key] != fingerprint
+}
+
+func (r *HealthCheckReconciler) rememberCredentials(key, fingerprint string) {
+	r.credentialsMu.Lock()
+	defer r.credentialsMu.Unlock()
+	if fingerprint == "" {
+		delete(r.credentials, key)
+		return
+	}
+	if r.credentials == nil {
+		r.credentials = make(map[string]string)
+	}
+	r.credentials[key] = fingerprint
+}
+
+// secretToHealthChecks enqueues the HealthChecks that read credentials from a changed Secret
+func secretToHealthChecks(c client.Reader) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var healthChecks healthv1alpha1.HealthCheckList
+		if err := c.List(ctx, &healthChecks, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, healthCheck := range healthChecks.Items {
+			if !referencesSecret(healthCheck.Spec, obj.GetName()) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: healthCheck.Namespace, Name: healthCheck.Name},
+			})
+		}
+		return requests
+	}
+}
+
+func referencesSecret(spec healthv1alpha1.HealthCheckSpec, name string) bool {
+	for _, check := range spec.Checks {
+		if check.AuthSecretRef != nil && check.AuthSecretRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	healthv1alpha1 "github.com/kdwils/constellation/api/v1alpha1"
+)
+
+func newTestSecret(name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       make(map[string][]byte),
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
+func TestAuthorizationFromSecret(t *testing.T) {
+	secret := *newTestSecret("creds", map[string]string{
+		"token":    "abc123\n",
+		"username": "admin",
+		"password": "hunter2",
+	})
+
+	tests := []struct {
+		name    string
+		ref     healthv1alpha1.AuthSecretRef
+		want    string
+		wantErr string
+	}{
+		{
+			name: "bearer token",
+			ref:  healthv1alpha1.AuthSecretRef{Name: "creds", TokenKey: "token"},
+			want: "Bearer abc123",
+		},
+		{
+			name: "basic auth",
+			ref:  healthv1alpha1.AuthSecretRef{Name: "creds", UsernameKey: "username", PasswordKey: "password"},
+			want: "Basic YWRtaW46aHVudGVyMg==",
+		},
+		{
+			name:    "missing key",
+			ref:     healthv1alpha1.AuthSecretRef{Name: "creds", TokenKey: "missing"},
+			wantErr: `secret "creds" has no key "missing"`,
+		},
+		{
+			name:    "token and basic auth",
+			ref:     healthv1alpha1.AuthSecretRef{Name: "creds", TokenKey: "token", UsernameKey: "username"},
+			wantErr: "not both",
+		},
+		{
+			name:    "username without password",
+			ref:     healthv1alpha1.AuthSecretRef{Name: "creds", UsernameKey: "username"},
+			wantErr: "must set tokenKey or both",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authorizationFromSecret(tt.ref, secret)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("authorizationFromSecret() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorizationFromSecret() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("authorizationFromSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReconciler_ResolvesAuthSecret(t *testing.T) {
+	healthCheck := newTestHealthCheck("api", "default", 1)
+	healthCheck.Spec.Checks[0].AuthSecretRef = &healthv1alpha1.AuthSecretRef{Name: "api-creds", TokenKey: "token"}
+	secret := newTestSecret("api-creds", map[string]string{"token": "first"})
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(healthCheck, secret).
+		WithStatusSubresource(healthCheck).
+		Build()
+	registry := &fakeRegistry{}
+	r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	registrations := registry.registrations()
+	if len(registrations) != 1 {
+		t.Fatalf("Reconcile() registrations = %v, want 1", len(registrations))
+	}
+	if got := registrations[0].checks[0].Authorization; got != "Bearer first" {
+		t.Errorf("Reconcile() authorization = %q, want %q", got, "Bearer first")
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := len(registry.registrations()); got != 1 {
+		t.Errorf("Reconcile() with unchanged secret registrations = %v, want 1", got)
+	}
+
+	secret.Data["token"] = []byte("rotated")
+	if err := c.Update(context.Background(), secret); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	registrations = registry.registrations()
+	if len(registrations) != 2 {
+		t.Fatalf("Reconcile() after rotation registrations = %v, want 2", len(registrations))
+	}
+	if got := registrations[1].checks[0].Authorization; got != "Bearer rotated" {
+		t.Errorf("Reconcile() after rotation authorization = %q, want %q", got, "Bearer rotated")
+	}
+}
+
+func TestHealthCheckReconciler_MissingAuthSecret(t *testing.T) {
+	healthCheck := newTestHealthCheck("api", "default", 1)
+	healthCheck.Spec.Checks[0].AuthSecretRef = &healthv1alpha1.AuthSecretRef{Name: "missing", TokenKey: "token"}
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(healthCheck).
+		WithStatusSubresource(healthCheck).
+		Build()
+	registry := &fakeRegistry{}
+	r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := len(registry.registrations()); got != 0 {
+		t.Errorf("Reconcile() registrations = %v, want 0", got)
+	}
+
+	var got healthv1alpha1.HealthCheck
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(got.Status.Conditions, conditionAvailable)
+	if condition == nil || condition.Reason != reasonCredentialsUnavailable {
+		t.Errorf("Status.Conditions = %+v, want reason %s", got.Status.Conditions, reasonCredentialsUnavailable)
+	}
+}
+
+func TestSecretToHealthChecks(t *testing.T) {
+	referencing := newTestHealthCheck("api", "default", 1)
+	referencing.Spec.Checks[0].AuthSecretRef = &healthv1alpha1.AuthSecretRef{Name: "api-creds", TokenKey: "token"}
+	unrelated := newTestHealthCheck("web", "default", 1)
+	otherNamespace := newTestHealthCheck("api", "other", 1)
+	otherNamespace.Spec.Checks[0].AuthSecretRef = &healthv1alpha1.AuthSecretRef{Name: "api-creds", TokenKey: "token"}
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(referencing, unrelated, otherNamespace).
+		Build()
+
+	// The Secret watch only carries metadata
+	secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "api-creds", Namespace: "default"}}
+	requests := secretToHealthChecks(c)(context.Background(), secret)
+	if len(requests) != 1 || requests[0].Name != "api" || requests[0].Namespace != "default" {
+		t.Errorf("secretToHealthChecks() = %v, want default/api only", requests)
+	}
+}
+
+func TestHealthCheckReconciler_ReadsSecretsThroughAPIReader(t *testing.T) {
+	healthCheck := newTestHealthCheck("api", "default", 1)
+	healthCheck.Spec.Checks[0].AuthSecretRef = &healthv1alpha1.AuthSecretRef{Name: "api-creds", TokenKey: "token"}
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(healthCheck).
+		WithStatusSubresource(healthCheck).
+		Build()
+	apiReader := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(newTestSecret("api-creds", map[string]string{"token": "uncached"})).
+		Build()
+	registry := &fakeRegistry{}
+	r := &HealthCheckReconciler{Client: c, APIReader: apiReader, HealthChecker: registry}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	registrations := registry.registrations()
+	if len(registrations) != 1 {
+		t.Fatalf("Reconcile() registrations = %v, want 1", len(registrations))
+	}
+	if got := registrations[0].checks[0].Authorization; got != "Bearer uncached" {
+		t.Errorf("Reconcile() authorization = %q, want %q", got, "Bearer uncached")
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer runs only the most recent function scheduled for a key once the
+// key has been quiet for the configured window
+type debouncer struct {
+	mu     sync.Mutex
+	window time.Duration
+	timers map[string]*time.Timer
+}
+
+func newDebouncer(window time.Duration) *debouncer {
+	return &debouncer{
+		window: window,
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// Do schedules fn for key, replacing anything already pending for it
+func (d *debouncer) Do(key string, fn func()) {
+	if d.window <= 0 {
+		fn()
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if pending, exists := d.timers[key]; exists {
+		pending.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		if d.timers[key] != timer {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.timers, key)
+		d.mu.Unlock()
+
+		fn()
+	})
+	d.timers[key] = timer
+}
+
+// Cancel drops anything pending for key
+func (d *debouncer) Cancel(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if pending, exists := d.timers[key]; exists {
+		pending.Stop()
+		delete(d.timers, key)
+	}
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kdwils/constellation/internal/healthcheck"
+	healthtypes "github.com/kdwils/constellation/internal/types"
+)
+
+type registration struct {
+	name   string
+	checks []healthcheck.CheckConfig
+}
+
+type fakeRegistry struct {
+	mu           sync.Mutex
+	registered   []registration
+	unregistered []string
+	metadata     map[string]healthcheck.TargetMetadata
+	maintenance  map[string]bool
+	health       map[string]*healthtypes.ServiceHealthInfo
+}
+
+func (f *fakeRegistry) RegisterHealthTarget(name string, checks []healthcheck.CheckConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registered = append(f.registered, registration{name: name, checks: checks})
+}
+
+func (f *fakeRegistry) UnregisterHealthTarget(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unregistered = append(f.unregistered, name)
+}
+
+func (f *fakeRegistry) SetTargetMetadata(name string, metadata healthcheck.TargetMetadata) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.metadata == nil {
+		f.metadata = make(map[string]healthcheck.TargetMetadata)
+	}
+	f.metadata[name] = metadata
+}
+
+func (f *fakeRegistry) SetMaintenance(name string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.maintenance == nil {
+		f.maintenance = make(map[string]bool)
+	}
+	f.maintenance[name] = enabled
+}
+
+func (f *fakeRegistry) targetMetadata(name string) healthcheck.TargetMetadata {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.metadata[name]
+}
+
+func (f *fakeRegistry) IsRegistered(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.registered {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeRegistry) GetHealthData(name string) (*healthtypes.ServiceHealthInfo, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, exists := f.health[name]
+	return info, exists
+}
+
+func (f *fakeRegistry) registrations() []registration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]registration(nil), f.registered...)
+}
+
+func TestServiceReconciler_DebouncesRegistration(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+
+	tests := []struct {
+		name      string
+		window    time.Duration
+		reconcile int
+		wantCount int
+	}{
+		{
+			name:      "burst is registered once",
+			window:    50 * time.Millisecond,
+			reconcile: 5,
+			wantCount: 1,
+		},
+		{
+			name:      "no debounce registers every reconcile",
+			window:    0,
+			reconcile: 3,
+			wantCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+			c := fake.NewClientBuilder().WithObjects(&service, &pod).Build()
+			registry := &fakeRegistry{}
+			r := &ServiceReconciler{
+				Client:        c,
+				HealthChecker: registry,
+				registrations: newDebouncer(tt.window),
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			for i := range tt.reconcile {
+				pod.Spec.Containers[0].LivenessProbe.HTTPGet.Path = fmt.Sprintf("/healthz/%d", i)
+				if err := c.Update(context.Background(), &pod); err != nil {
+					t.Fatalf("failed to update pod: %v", err)
+				}
+				if _, err := r.Reconcile(context.Background(), req); err != nil {
+					t.Fatalf("Reconcile() error = %v", err)
+				}
+			}
+
+			time.Sleep(tt.window * 4)
+
+			got := registry.registrations()
+			if len(got) != tt.wantCount {
+				t.Fatalf("Reconcile() registrations = %v, want %v", len(got), tt.wantCount)
+			}
+			wantURL := fmt.Sprintf("http://api.default.svc.cluster.local:80/healthz/%d", tt.reconcile-1)
+			last := got[len(got)-1]
+			if last.checks[0].URL != wantURL {
+				t.Errorf("Reconcile() last registered url = %v, want %v", last.checks[0].URL, wantURL)
+			}
+		})
+	}
+}
+
+func TestServiceReconciler_DeleteCancelsPendingRegistration(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+
+	c := fake.NewClientBuilder().WithObjects(&service, &pod).Build()
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        c,
+		HealthChecker: registry,
+		registrations: newDebouncer(50 * time.Millisecond),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := c.Delete(context.Background(), &service); err != nil {
+		t.Fatalf("failed to delete service: %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if got := registry.registrations(); len(got) != 0 {
+		t.Errorf("Reconcile() registrations after delete = %v, want 0", len(got))
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+
+// endpointSliceToService maps an EndpointSlice event to a reconcile of the service that owns it
+func endpointSliceToService(_ context.Context, obj client.Object) []reconcile.Request {
+	serviceName := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: serviceName}},
+	}
+}
+
+// groupEndpointSlicesByService indexes slices by the name of the service they belong to
+func groupEndpointSlicesByService(slices []discoveryv1.EndpointSlice) map[string][]discoveryv1.EndpointSlice {
+	grouped := make(map[string][]discoveryv1.EndpointSlice)
+	for _, slice := range slices {
+		serviceName := slice.Labels[discoveryv1.LabelServiceName]
+		if serviceName == "" {
+			continue
+		}
+		grouped[serviceName] = append(grouped[serviceName], slice)
+	}
+	return grouped
+}
+
+// findBackendPods returns the pods actually backing a service. Ready endpoints
+// from the service's EndpointSlices are authoritative; selector matching is
+// only used when the service has no slices at all.
+func findBackendPods(service corev1.Service, slices []discoveryv1.EndpointSlice, pods []corev1.Pod) []corev1.Pod {
+	var backends []corev1.Pod
+	if len(slices) == 0 {
+		for _, pod := range pods {
+			if labelsMatch(service.Spec.Selector, pod.Labels) {
+				backends = append(backends, pod)
+			}
+		}
+		return backends
+	}
+
+	ready := make(map[string]bool)
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			ready[endpoint.TargetRef.Name] = true
+		}
+	}
+
+	for _, pod := range pods {
+		if ready[pod.Name] {
+			backends = append(backends, pod)
+		}
+	}
+	return backends
+}
+
+// isBackendPod reports whether the named pod is one of a service's backends
+func isBackendPod(name string, backends []corev1.Pod) bool {
+	for _, pod := range backends {
+		if pod.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestEndpointSlice(name, namespace, serviceName string, endpoints ...discoveryv1.Endpoint) discoveryv1.EndpointSlice {
+	return discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+	}
+}
+
+func newTestEndpoint(podName string, ready *bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{"10.0.0.1"},
+		Conditions: discoveryv1.EndpointConditions{Ready: ready},
+		TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: podName},
+	}
+}
+
+func TestFindBackendPods(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector)
+
+	labeled := newTestPod("labeled", "default", selector)
+	manual := newTestPod("manual", "default", map[string]string{"app": "legacy"})
+	unready := newTestPod("unready", "default", selector)
+	pods := []corev1.Pod{labeled, manual, unready}
+
+	tests := []struct {
+		name   string
+		slices []discoveryv1.EndpointSlice
+		want   []string
+	}{
+		{
+			name: "selector matching without slices",
+			want: []string{"labeled", "unready"},
+		},
+		{
+			name: "endpoint slices take precedence over selector",
+			slices: []discoveryv1.EndpointSlice{
+				newTestEndpointSlice("api-abc", "default", "api",
+					newTestEndpoint("manual", ptr.To(true)),
+					newTestEndpoint("unready", ptr.To(false))),
+			},
+			want: []string{"manual"},
+		},
+		{
+			name: "endpoints without ready condition are treated as ready",
+			slices: []discoveryv1.EndpointSlice{
+				newTestEndpointSlice("api-abc", "default", "api", newTestEndpoint("labeled", nil)),
+			},
+			want: []string{"labeled"},
+		},
+		{
+			name: "endpoints spread across slices",
+			slices: []discoveryv1.EndpointSlice{
+				newTestEndpointSlice("api-abc", "default", "api", newTestEndpoint("labeled", ptr.To(true))),
+				newTestEndpointSlice("api-def", "default", "api", newTestEndpoint("manual", ptr.To(true))),
+			},
+			want: []string{"labeled", "manual"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, pod := range findBackendPods(service, tt.slices, pods) {
+				got = append(got, pod.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findBackendPods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBackendPod(t *testing.T) {
+	service := newTestService("external", "default", nil)
+	legacy := newTestPod("legacy-0", "default", map[string]string{"app": "legacy"})
+	other := newTestPod("other-0", "default", map[string]string{"app": "other"})
+	pods := []corev1.Pod{legacy, other}
+	slices := []discoveryv1.EndpointSlice{
+		newTestEndpointSlice("external-abc", "default", "external", newTestEndpoint("legacy-0", ptr.To(true))),
+	}
+
+	tests := []struct {
+		name string
+		pod  string
+		want bool
+	}{
+		{name: "pod listed by a selectorless service's slice", pod: "legacy-0", want: true},
+		{name: "pod not in the slice", pod: "other-0", want: false},
+		{name: "deleted pod", pod: "gone-0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBackendPod(tt.pod, findBackendPods(service, slices, pods)); got != tt.want {
+				t.Errorf("isBackendPod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceReconciler_EndpointSliceBackends(t *testing.T) {
+	service := newTestService("external", "default", nil,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("legacy-0", "default", map[string]string{"app": "legacy"},
+		newHTTPProbeContainer("app", 8080, "/healthz"))
+	slice := newTestEndpointSlice("external-abc", "default", "external", newTestEndpoint("legacy-0", ptr.To(true)))
+
+	c := fake.NewClientBuilder().WithObjects(&service, &pod, &slice).Build()
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        c,
+		HealthChecker: registry,
+		registrations: newDebouncer(0),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "external"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	got := registry.registrations()
+	if len(got) != 1 {
+		t.Fatalf("Reconcile() registrations = %v, want 1", len(got))
+	}
+	wantURL := "http://external.default.svc.cluster.local:80/healthz"
+	if got[0].name != "default/external" || got[0].checks[0].URL != wantURL {
+		t.Errorf("Reconcile() registered %s %s, want default/external %s", got[0].name, got[0].checks[0].URL, wantURL)
+	}
+}
+
+func TestServiceReconciler_RequeuesAfterCompletedPodGrace(t *testing.T) {
+	selector := map[string]string{"app": "job"}
+	service := newTestService("job", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("job-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+	pod.Status.Phase = corev1.PodSucceeded
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			FinishedAt: metav1.NewTime(time.Now().Add(-30 * time.Second)),
+		}},
+	}}
+
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        fake.NewClientBuilder().WithObjects(&service, &pod).Build(),
+		HealthChecker: registry,
+		options:       DiscoveryOptions{CompletedPodGrace: time.Minute},
+		registrations: newDebouncer(0),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "job"}}
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Errorf("Reconcile() RequeueAfter = %v, want within (0, 1m]", result.RequeueAfter)
+	}
+	if got := registry.registrations(); len(got) != 1 {
+		t.Errorf("Reconcile() registrations = %v, want 1", len(got))
+	}
+}
+
+func TestEndpointSliceToService(t *testing.T) {
+	tests := []struct {
+		name  string
+		slice discoveryv1.EndpointSlice
+		want  int
+	}{
+		{
+			name:  "slice owned by a service",
+			slice: newTestEndpointSlice("api-abc", "default", "api"),
+			want:  1,
+		},
+		{
+			name: "slice without service label",
+			slice: discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: "default"},
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := endpointSliceToService(context.Background(), &tt.slice)
+			if len(got) != tt.want {
+				t.Fatalf("endpointSliceToService() = %v, want %v requests", got, tt.want)
+			}
+			if tt.want == 1 && got[0].Name != "api" {
+				t.Errorf("endpointSliceToService() name = %v, want api", got[0].Name)
+			}
+		})
+	}
+}
+
+func TestServiceReconciler_ReportsUnmappedProbePort(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 9090, "/healthz"))
+
+	c := fake.NewClientBuilder().WithObjects(&service, &pod).Build()
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        c,
+		HealthChecker: registry,
+		registrations: newDebouncer(0),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if got := registry.registrations(); len(got) != 0 {
+		t.Errorf("Reconcile() registrations = %v, want 0", len(got))
+	}
+	if got := registry.targetMetadata("default/api").Warnings; len(got) != 1 {
+		t.Errorf("Reconcile() warnings = %v, want 1", got)
+	}
+}
+
+func TestServiceReconciler_ReportsGroup(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	service.Annotations = map[string]string{groupAnnotation: "payments", maintenanceAnnotation: "true"}
+	pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        fake.NewClientBuilder().WithObjects(&service, &pod).Build(),
+		HealthChecker: registry,
+		registrations: newDebouncer(0),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if got := registry.targetMetadata("default/api").Group; got != "payments" {
+		t.Errorf("Reconcile() group = %v, want payments", got)
+	}
+	if !registry.targetMetadata("default/api").Maintenance {
+		t.Errorf("Reconcile() maintenance = false, want true")
+	}
+}
+
+func TestServiceReconciler_ExternalName(t *testing.T) {
+	service := newTestService("billing", "default", nil, corev1.ServicePort{Port: 443})
+	service.Spec.Type = corev1.ServiceTypeExternalName
+	service.Spec.ExternalName = "billing.example.com"
+	pod := newTestPod("billing-0", "default", map[string]string{"app": "billing"},
+		newHTTPProbeContainer("app", 443, "/healthz"))
+	slice := newTestEndpointSlice("billing-abc", "default", "billing", newTestEndpoint("billing-0", ptr.To(true)))
+
+	registry := &fakeRegistry{}
+	r := &ServiceReconciler{
+		Client:        fake.NewClientBuilder().WithObjects(&service, &pod, &slice).Build(),
+		HealthChecker: registry,
+		registrations: newDebouncer(0),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "billing"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if got := registry.registrations(); len(got) != 0 {
+		t.Errorf("Reconcile() registrations = %v, want 0", len(got))
+	}
+	if got := registry.targetMetadata("default/billing").ExternalName; got != "billing.example.com" {
+		t.Errorf("Reconcile() external name = %q, want billing.example.com", got)
+	}
+}
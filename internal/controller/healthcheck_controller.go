@@ -18,27 +18,71 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	healthv1alpha1 "github.com/kdwils/constellation/api/v1alpha1"
 	"github.com/kdwils/constellation/internal/healthcheck"
+	healthtypes "github.com/kdwils/constellation/internal/types"
 )
 
 const healthCheckFinalizer = "health.kyledev.co/finalizer"
 
+const (
+	// conditionAvailable reports whether the checks in the spec are registered
+	conditionAvailable = "Available"
+
+	reasonRegistered             = "Registered"
+	reasonInvalidSpec            = "InvalidSpec"
+	reasonCredentialsUnavailable = "CredentialsUnavailable"
+)
+
 // HealthCheckReconciler reconciles a HealthCheck object
 type HealthCheckReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	HealthChecker *healthcheck.HealthChecker
+	HealthChecker HealthTargetMonitor
+	// Discovery configures the checks derived for services matched by a selector
+	Discovery []DiscoveryOpt
+	// APIReader reads referenced Secrets straight from the API server, so they
+	// are never cached. Client is used when it is nil.
+	APIReader client.Reader
+
+	// credentials holds a fingerprint of the credentials last registered per
+	// target, so a rotated Secret re-registers without a spec change
+	credentialsMu sync.Mutex
+	credentials   map[string]string
+
+	// selectedChecks holds the checks last registered per selected service
+	// target, so status refreshes do not re-register unchanged checks
+	selectedMu     sync.Mutex
+	selectedChecks map[string][]healthcheck.CheckConfig
 }
 
+// defaultStatusRefresh is how often status is refreshed when no check sets an interval
+const defaultStatusRefresh = 30 * time.Second
+
 // +kubebuilder:rbac:groups=health.kyledev.co,resources=healthchecks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=health.kyledev.co,resources=healthchecks/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=health.kyledev.co,resources=healthchecks/finalizers,verbs=update
@@ -46,6 +90,8 @@ type HealthCheckReconciler struct {
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *HealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer observeReconcile("healthcheck", time.Now())
+
 	logger := logf.FromContext(ctx)
 
 	var healthCheck healthv1alpha1.HealthCheck
@@ -59,6 +105,8 @@ func (r *HealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if controllerutil.ContainsFinalizer(&healthCheck, healthCheckFinalizer) {
 			logger.Info("unregistering health check", "service", serviceKey)
 			r.HealthChecker.UnregisterHealthTarget(serviceKey)
+			r.rememberCredentials(serviceKey, "")
+			r.unregisterDeselected(&healthCheck, healthCheck.Status.SelectedServices, nil)
 
 			controllerutil.RemoveFinalizer(&healthCheck, healthCheckFinalizer)
 			if err := r.Update(ctx, &healthCheck); err != nil {
@@ -70,8 +118,61 @@ func (r *HealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	checks := convertToCheckConfigs(healthCheck.Spec.Checks)
 
-	logger.Info("registering custom health check", "identifier", serviceKey, "checks", len(checks))
-	r.HealthChecker.RegisterHealthTarget(serviceKey, checks)
+	if err := validateSpec(healthCheck.Namespace, healthCheck.Spec, checks); err != nil {
+		logger.Info("rejecting invalid health check", "service", serviceKey, "reason", err.Error())
+		if r.HealthChecker.IsRegistered(serviceKey) {
+			r.HealthChecker.UnregisterHealthTarget(serviceKey)
+		}
+		r.rememberCredentials(serviceKey, "")
+		r.unregisterDeselected(&healthCheck, healthCheck.Status.SelectedServices, nil)
+		// The spec has to change before this can succeed, which triggers a new reconcile
+		return ctrl.Result{}, r.updateStatus(ctx, &healthCheck, serviceKey, metav1.Condition{
+			Type:    conditionAvailable,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonInvalidSpec,
+			Message: err.Error(),
+		}, nil)
+	}
+
+	if err := resolveAuthorization(ctx, r.secretReader(), healthCheck.Namespace, healthCheck.Spec.Checks, checks); err != nil {
+		logger.Info("credentials unavailable", "service", serviceKey, "reason", err.Error())
+		if r.HealthChecker.IsRegistered(serviceKey) {
+			r.HealthChecker.UnregisterHealthTarget(serviceKey)
+		}
+		r.rememberCredentials(serviceKey, "")
+		// The Secret watch triggers a new reconcile once the Secret is fixed
+		return ctrl.Result{}, r.updateStatus(ctx, &healthCheck, serviceKey, metav1.Condition{
+			Type:    conditionAvailable,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonCredentialsUnavailable,
+			Message: err.Error(),
+		}, healthCheck.Status.SelectedServices)
+	}
+
+	// Status refreshes requeue this object, so only register when the spec or
+	// credentials changed, or the target is missing. Re-registering every time
+	// would undo runtime overrides.
+	specChanged := healthCheck.Status.ObservedGeneration != healthCheck.Generation
+	fingerprint := credentialsFingerprint(checks)
+	r.HealthChecker.SetMaintenance(serviceKey, inMaintenance(healthCheck.Annotations))
+	switch {
+	case len(checks) == 0 && r.HealthChecker.IsRegistered(serviceKey):
+		r.HealthChecker.UnregisterHealthTarget(serviceKey)
+		r.rememberCredentials(serviceKey, "")
+	case len(checks) > 0 && (specChanged || !r.HealthChecker.IsRegistered(serviceKey) ||
+		r.credentialsChanged(serviceKey, fingerprint)):
+		logger.Info("registering custom health check", "identifier", serviceKey, "checks", len(checks))
+		r.HealthChecker.RegisterHealthTarget(serviceKey, checks)
+		r.rememberCredentials(serviceKey, fingerprint)
+	}
+
+	selected, discovered, err := r.registerSelectedServices(ctx, &healthCheck)
+	if err != nil {
+		logger.Error(err, "failed to register selected services")
+		return ctrl.Result{}, err
+	}
+	r.unregisterDeselected(&healthCheck, healthCheck.Status.SelectedServices, selected)
+	checks = append(checks, discovered...)
 
 	if !controllerutil.ContainsFinalizer(&healthCheck, healthCheckFinalizer) {
 		logger.Info("adding finalizer")
@@ -81,18 +182,370 @@ func (r *HealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
-	return ctrl.Result{}, nil
+	if err := r.updateStatus(ctx, &healthCheck, serviceKey, metav1.Condition{
+		Type:    conditionAvailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonRegistered,
+		Message: fmt.Sprintf("%d health checks registered", len(checks)),
+	}, selected); err != nil {
+		logger.Error(err, "failed to update health check status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: statusRefreshInterval(checks)}, nil
+}
+
+// updateStatus copies the latest result for the target, the given condition and
+// the selected services into the HealthCheck status
+func (r *HealthCheckReconciler) updateStatus(
+	ctx context.Context,
+	healthCheck *healthv1alpha1.HealthCheck,
+	serviceKey string,
+	condition metav1.Condition,
+	selected []string,
+) error {
+	status := *healthCheck.Status.DeepCopy()
+	status.ObservedGeneration = healthCheck.Generation
+	status.SelectedServices = selected
+	condition.ObservedGeneration = healthCheck.Generation
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	if info, exists := r.HealthChecker.GetHealthData(serviceKey); exists {
+		applyHealthInfo(&status, info)
+	}
+
+	if equality.Semantic.DeepEqual(status, healthCheck.Status) {
+		return nil
+	}
+
+	original := healthCheck.DeepCopy()
+	healthCheck.Status = status
+	return r.Status().Patch(ctx, healthCheck, client.MergeFrom(original))
+}
+
+func applyHealthInfo(status *healthv1alpha1.HealthCheckStatus, info *healthtypes.ServiceHealthInfo) {
+	status.Status = string(info.Status)
+	status.Uptime = fmt.Sprintf("%.1f%%", info.Uptime)
+	status.LastError = ""
+	status.LastCheck = nil
+
+	if !info.LastCheck.IsZero() {
+		lastCheck := metav1.NewTime(info.LastCheck)
+		status.LastCheck = &lastCheck
+	}
+	if len(info.History) > 0 {
+		status.LastError = info.History[len(info.History)-1].Error
+	}
+}
+
+// statusRefreshInterval returns the shortest check interval so status keeps up with results
+func statusRefreshInterval(checks []healthcheck.CheckConfig) time.Duration {
+	var interval time.Duration
+	for _, check := range checks {
+		if check.Interval <= 0 {
+			continue
+		}
+		if interval == 0 || check.Interval < interval {
+			interval = check.Interval
+		}
+	}
+	if interval == 0 {
+		return defaultStatusRefresh
+	}
+	return interval
+}
+
+// registerSelectedServices registers the discovered checks of every service
+// matched by the service selector under its selectedTargetKey. Checks are only
+// re-registered when they change. It returns the keys of the matched services,
+// sorted, and their checks.
+func (r *HealthCheckReconciler) registerSelectedServices(
+	ctx context.Context,
+	healthCheck *healthv1alpha1.HealthCheck,
+) ([]string, []healthcheck.CheckConfig, error) {
+	if healthCheck.Spec.ServiceSelector == nil {
+		return nil, nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&healthCheck.Spec.ServiceSelector.LabelSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var services corev1.ServiceList
+	if err := r.List(ctx, &services, client.InNamespace(selectorNamespace(healthCheck)),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, nil, fmt.Errorf("listing services: %w", err)
+	}
+
+	var selected []string
+	var registered []healthcheck.CheckConfig
+	for _, service := range services.Items {
+		if shouldIgnoreResource(service.Annotations) {
+			continue
+		}
+		checks, _, _, err := discoverServiceChecks(ctx, r.Client, service, newDiscoveryOptions(r.Discovery...))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(checks) == 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+		target := selectedTargetKey(healthCheck, key)
+		if !r.HealthChecker.IsRegistered(target) || r.selectedChecksChanged(target, checks) {
+			r.HealthChecker.RegisterHealthTarget(target, checks)
+			r.rememberSelectedChecks(target, checks)
+		}
+		r.HealthChecker.SetMaintenance(target, inMaintenance(healthCheck.Annotations))
+		selected = append(selected, key)
+		registered = append(registered, checks...)
+	}
+
+	slices.Sort(selected)
+	return selected, registered, nil
+}
+
+// unregisterDeselected unregisters the targets of services from previous that
+// are not in current
+func (r *HealthCheckReconciler) unregisterDeselected(healthCheck *healthv1alpha1.HealthCheck, previous, current []string) {
+	for _, key := range previous {
+		if slices.Contains(current, key) {
+			continue
+		}
+		target := selectedTargetKey(healthCheck, key)
+		r.HealthChecker.UnregisterHealthTarget(target)
+		r.rememberSelectedChecks(target, nil)
+	}
+}
+
+// selectedTargetKey is the target a selected service is registered under. The
+// Service reconciler owns the service's own key, so sharing it would let each
+// reconciler replace or remove the other's checks.
+func selectedTargetKey(healthCheck *healthv1alpha1.HealthCheck, serviceKey string) string {
+	return fmt.Sprintf("%s@%s.%s", serviceKey, healthCheck.Namespace, healthCheck.Name)
+}
+
+func (r *HealthCheckReconciler) selectedChecksChanged(target string, checks []healthcheck.CheckConfig) bool {
+	r.selectedMu.Lock()
+	defer r.selectedMu.Unlock()
+	return !slices.Equal(r.selectedChecks[target], checks)
+}
+
+func (r *HealthCheckReconciler) rememberSelectedChecks(target string, checks []healthcheck.CheckConfig) {
+	r.selectedMu.Lock()
+	defer r.selectedMu.Unlock()
+	if len(checks) == 0 {
+		delete(r.selectedChecks, target)
+		return
+	}
+	if r.selectedChecks == nil {
+		r.selectedChecks = make(map[string][]healthcheck.CheckConfig)
+	}
+	r.selectedChecks[target] = slices.Clone(checks)
+}
+
+func selectorNamespace(healthCheck *healthv1alpha1.HealthCheck) string {
+	if namespace := healthCheck.Spec.ServiceSelector.Namespace; namespace != "" {
+		return namespace
+	}
+	return healthCheck.Namespace
+}
+
+// serviceToHealthChecks enqueues the HealthChecks whose service selector matches a changed service
+func serviceToHealthChecks(c client.Reader) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var healthChecks healthv1alpha1.HealthCheckList
+		if err := c.List(ctx, &healthChecks); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range healthChecks.Items {
+			healthCheck := &healthChecks.Items[i]
+			if healthCheck.Spec.ServiceSelector == nil || selectorNamespace(healthCheck) != obj.GetNamespace() {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(&healthCheck.Spec.ServiceSelector.LabelSelector)
+			if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: healthCheck.Namespace, Name: healthCheck.Name},
+			})
+		}
+		return requests
+	}
+}
+
+// validateSpec rejects specs that select nothing to check, or that check
+// anything outside of namespace, the HealthCheck's own
+func validateSpec(namespace string, spec healthv1alpha1.HealthCheckSpec, checks []healthcheck.CheckConfig) error {
+	if len(checks) == 0 && spec.ServiceSelector == nil {
+		return errors.New("spec must set checks or serviceSelector")
+	}
+	if spec.ServiceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(&spec.ServiceSelector.LabelSelector); err != nil {
+			return fmt.Errorf("invalid service selector: %w", err)
+		}
+	}
+	return validateChecks(namespace, checks)
+}
+
+// validateChecks rejects checks the health checker could never run, so they
+// are reported on the resource instead of failing on every tick. Results are
+// tracked per check name, so names must be unique.
+func validateChecks(namespace string, checks []healthcheck.CheckConfig) error {
+	seen := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		if err := validateSchedule(check); err != nil {
+			return fmt.Errorf("check %q: %w", check.Name, err)
+		}
+		if err := validateCheck(namespace, check); err != nil {
+			return fmt.Errorf("check %q: %w", check.Name, err)
+		}
+		if seen[check.Name] {
+			return fmt.Errorf("check %q: duplicate check name", check.Name)
+		}
+		seen[check.Name] = true
+	}
+	return nil
+}
+
+// validateSchedule rejects intervals and timeouts the checker cannot tick or
+// wait on
+func validateSchedule(check healthcheck.CheckConfig) error {
+	if check.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", check.Interval)
+	}
+	if check.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", check.Timeout)
+	}
+	return nil
+}
+
+func validateCheck(namespace string, check healthcheck.CheckConfig) error {
+	if check.ExpectJSONValue != "" && check.ExpectJSONPath == "" {
+		return fmt.Errorf("expectJSONValue requires expectJSONPath")
+	}
+
+	switch check.Protocol {
+	case "http", "https":
+		return validateHTTPURL(check)
+	case "tcp", "grpc", "udp", "icmp", "replicas":
+	default:
+		return fmt.Errorf("unsupported protocol %q", check.Protocol)
+	}
+
+	if check.ExpectBodyContains != "" || check.ExpectJSONPath != "" {
+		return fmt.Errorf("%s checks have no response body to match", check.Protocol)
+	}
+	if check.Payload != "" && check.Protocol != "udp" {
+		return fmt.Errorf("payload is only sent by udp checks")
+	}
+	if check.Method != "" {
+		return fmt.Errorf("method is only used by http(s) checks")
+	}
+	if check.RequestBody != "" && check.Protocol != "grpc" {
+		return fmt.Errorf("requestBody is only sent by http(s) and grpc checks")
+	}
+	if check.ContentType != "" {
+		return fmt.Errorf("contentType is only sent by http(s) checks")
+	}
+
+	switch check.Protocol {
+	case "tcp":
+		if _, _, err := net.SplitHostPort(strings.TrimPrefix(check.URL, "tcp://")); err != nil {
+			return fmt.Errorf("tcp url must be host:port: %w", err)
+		}
+	case "udp":
+		if _, _, err := net.SplitHostPort(strings.TrimPrefix(check.URL, "udp://")); err != nil {
+			return fmt.Errorf("udp url must be udp://host:port: %w", err)
+		}
+	case "icmp":
+		host, found := strings.CutPrefix(check.URL, "icmp://")
+		if !found || host == "" || strings.Contains(host, "/") {
+			return fmt.Errorf("icmp url must be icmp://host")
+		}
+	case "replicas":
+		_, workloadNamespace, _, err := healthcheck.ParseWorkloadTarget(check.URL)
+		if err != nil {
+			return err
+		}
+		// The operator can read workloads cluster-wide, so a HealthCheck must
+		// not be a way to read them from namespaces its author has no access to
+		if workloadNamespace != namespace {
+			return fmt.Errorf("replicas checks can only read workloads in namespace %q", namespace)
+		}
+	case "grpc":
+		u, err := url.Parse(check.URL)
+		if err != nil || u.Scheme != "grpc" {
+			return fmt.Errorf("grpc url must be grpc://host:port[/service]")
+		}
+		if _, _, err := net.SplitHostPort(u.Host); err != nil {
+			return fmt.Errorf("grpc url must be grpc://host:port[/service]: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateHTTPURL(check healthcheck.CheckConfig) error {
+	if check.Payload != "" {
+		return fmt.Errorf("payload is only sent by udp checks")
+	}
+	if err := validateRequestBody(check); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(check.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q, want http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url %q has no host", check.URL)
+	}
+	return nil
+}
+
+func validateRequestBody(check healthcheck.CheckConfig) error {
+	if check.ContentType != "" && check.RequestBody == "" {
+		return fmt.Errorf("contentType requires requestBody")
+	}
+	if check.RequestBody == "" {
+		return nil
+	}
+	if !healthcheck.MethodAllowsBody(check.Method) {
+		return fmt.Errorf("requestBody is only sent with POST, PUT or PATCH")
+	}
+	if len(check.RequestBody) > healthcheck.MaxRequestBodyBytes {
+		return fmt.Errorf("requestBody exceeds %d bytes", healthcheck.MaxRequestBodyBytes)
+	}
+	return nil
 }
 
 func convertToCheckConfigs(apiChecks []healthv1alpha1.CheckConfig) []healthcheck.CheckConfig {
 	checks := make([]healthcheck.CheckConfig, len(apiChecks))
 	for i, apiCheck := range apiChecks {
 		checks[i] = healthcheck.CheckConfig{
-			Name:     apiCheck.Name,
-			URL:      apiCheck.URL,
-			Interval: apiCheck.Interval.Duration,
-			Timeout:  apiCheck.Timeout.Duration,
-			Protocol: apiCheck.Protocol,
+			Name:               apiCheck.Name,
+			URL:                apiCheck.URL,
+			Interval:           apiCheck.Interval.Duration,
+			Timeout:            apiCheck.Timeout.Duration,
+			Protocol:           apiCheck.Protocol,
+			DisableKeepAlives:  apiCheck.DisableKeepAlives,
+			InsecureSkipVerify: apiCheck.InsecureSkipVerify,
+			CABundle:           apiCheck.CABundle,
+			ExpectBodyContains: apiCheck.ExpectBodyContains,
+			ExpectJSONPath:     apiCheck.ExpectJSONPath,
+			ExpectJSONValue:    apiCheck.ExpectJSONValue,
+			Payload:            apiCheck.Payload,
+			Method:             apiCheck.Method,
+			RequestBody:        apiCheck.RequestBody,
+			ContentType:        apiCheck.ContentType,
 		}
 	}
 	return checks
@@ -102,6 +555,9 @@ func convertToCheckConfigs(apiChecks []healthv1alpha1.CheckConfig) []healthcheck
 func (r *HealthCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&healthv1alpha1.HealthCheck{}).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(serviceToHealthChecks(mgr.GetClient()))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(secretToHealthChecks(mgr.GetClient())),
+			builder.OnlyMetadata).
 		Named("healthcheck").
 		Complete(r)
 }
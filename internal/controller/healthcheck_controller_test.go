@@ -16,69 +16,694 @@ limitations under the License.
 
 package controller
 
-// import (
-// 	"context"
-
-// 	. "github.com/onsi/ginkgo/v2"
-// 	. "github.com/onsi/gomega"
-// 	"k8s.io/apimachinery/pkg/api/errors"
-// 	"k8s.io/apimachinery/pkg/types"
-// 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-
-// 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-// 	healthv1alpha1 "github.com/kdwils/constellation/api/v1alpha1"
-// )
-
-// var _ = Describe("HealthCheck Controller", func() {
-// 	Context("When reconciling a resource", func() {
-// 		const resourceName = "test-resource"
-
-// 		ctx := context.Background()
-
-// 		typeNamespacedName := types.NamespacedName{
-// 			Name:      resourceName,
-// 			Namespace: "default", // TODO(user):Modify as needed
-// 		}
-// 		healthcheck := &healthv1alpha1.HealthCheck{}
-
-// 		BeforeEach(func() {
-// 			By("creating the custom resource for the Kind HealthCheck")
-// 			err := k8sClient.Get(ctx, typeNamespacedName, healthcheck)
-// 			if err != nil && errors.IsNotFound(err) {
-// 				resource := &healthv1alpha1.HealthCheck{
-// 					ObjectMeta: metav1.ObjectMeta{
-// 						Name:      resourceName,
-// 						Namespace: "default",
-// 					},
-// 					// TODO(user): Specify other spec details if needed.
-// 				}
-// 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
-// 			}
-// 		})
-
-// 		AfterEach(func() {
-// 			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-// 			resource := &healthv1alpha1.HealthCheck{}
-// 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
-// 			Expect(err).NotTo(HaveOccurred())
-
-// 			By("Cleanup the specific resource instance HealthCheck")
-// 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
-// 		})
-// 		It("should successfully reconcile the resource", func() {
-// 			By("Reconciling the created resource")
-// 			controllerReconciler := &HealthCheckReconciler{
-// 				Client: k8sClient,
-// 				Scheme: k8sClient.Scheme(),
-// 			}
-
-// 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-// 				NamespacedName: typeNamespacedName,
-// 			})
-// 			Expect(err).NotTo(HaveOccurred())
-// 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-// 			// Example: If you expect a certain status condition after reconciliation, verify it here.
-// 		})
-// 	})
-// })
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	healthv1alpha1 "github.com/kdwils/constellation/api/v1alpha1"
+	"github.com/kdwils/constellation/internal/healthcheck"
+	healthtypes "github.com/kdwils/constellation/internal/types"
+)
+
+func newTestHealthCheck(name, namespace string, generation int64) *healthv1alpha1.HealthCheck {
+	return &healthv1alpha1.HealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			Generation: generation,
+			Finalizers: []string{healthCheckFinalizer},
+		},
+		Spec: healthv1alpha1.HealthCheckSpec{
+			Checks: []healthv1alpha1.CheckConfig{{
+				Name:     name + "-root",
+				URL:      "http://api.default.svc.cluster.local/healthz",
+				Interval: metav1.Duration{Duration: 15 * time.Second},
+				Timeout:  metav1.Duration{Duration: time.Second},
+				Protocol: "http",
+			}},
+		},
+	}
+}
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := healthv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return scheme
+}
+
+func TestHealthCheckReconciler_UpdatesStatus(t *testing.T) {
+	lastCheck := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		health        *healthtypes.ServiceHealthInfo
+		wantStatus    string
+		wantUptime    string
+		wantLastError string
+		wantLastCheck bool
+	}{
+		{
+			name: "healthy result",
+			health: &healthtypes.ServiceHealthInfo{
+				Status:    healthtypes.HealthStatusHealthy,
+				LastCheck: lastCheck,
+				Uptime:    100,
+				History:   []healthtypes.HealthCheckEntry{{Status: healthtypes.HealthStatusHealthy}},
+			},
+			wantStatus:    "healthy",
+			wantUptime:    "100.0%",
+			wantLastCheck: true,
+		},
+		{
+			name: "unhealthy result records the last error",
+			health: &healthtypes.ServiceHealthInfo{
+				Status:    healthtypes.HealthStatusUnhealthy,
+				LastCheck: lastCheck,
+				Uptime:    50,
+				History: []healthtypes.HealthCheckEntry{
+					{Status: healthtypes.HealthStatusHealthy},
+					{Status: healthtypes.HealthStatusUnhealthy, Error: "connection refused"},
+				},
+			},
+			wantStatus:    "unhealthy",
+			wantUptime:    "50.0%",
+			wantLastError: "connection refused",
+			wantLastCheck: true,
+		},
+		{
+			name: "no result yet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthCheck := newTestHealthCheck("api", "default", 1)
+			c := fake.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(healthCheck).
+				WithStatusSubresource(healthCheck).
+				Build()
+			registry := &fakeRegistry{}
+			if tt.health != nil {
+				registry.health = map[string]*healthtypes.ServiceHealthInfo{"default/api": tt.health}
+			}
+			r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			result, err := r.Reconcile(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if result.RequeueAfter != 15*time.Second {
+				t.Errorf("Reconcile() RequeueAfter = %v, want %v", result.RequeueAfter, 15*time.Second)
+			}
+
+			var got healthv1alpha1.HealthCheck
+			if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.Status.Status != tt.wantStatus {
+				t.Errorf("Status.Status = %v, want %v", got.Status.Status, tt.wantStatus)
+			}
+			if got.Status.Uptime != tt.wantUptime {
+				t.Errorf("Status.Uptime = %v, want %v", got.Status.Uptime, tt.wantUptime)
+			}
+			if got.Status.LastError != tt.wantLastError {
+				t.Errorf("Status.LastError = %v, want %v", got.Status.LastError, tt.wantLastError)
+			}
+			if (got.Status.LastCheck != nil) != tt.wantLastCheck {
+				t.Errorf("Status.LastCheck = %v, want set %v", got.Status.LastCheck, tt.wantLastCheck)
+			}
+			if got.Status.LastCheck != nil && !got.Status.LastCheck.Time.Equal(lastCheck) {
+				t.Errorf("Status.LastCheck = %v, want %v", got.Status.LastCheck.Time, lastCheck)
+			}
+			if got.Status.ObservedGeneration != 1 {
+				t.Errorf("Status.ObservedGeneration = %v, want 1", got.Status.ObservedGeneration)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReconciler_RegistersOnSpecChange(t *testing.T) {
+	tests := []struct {
+		name               string
+		generation         int64
+		observedGeneration int64
+		registered         bool
+		wantRegistrations  int
+	}{
+		{
+			name:               "new spec is registered",
+			generation:         2,
+			observedGeneration: 1,
+			registered:         true,
+			wantRegistrations:  2,
+		},
+		{
+			name:               "status refresh keeps the existing registration",
+			generation:         1,
+			observedGeneration: 1,
+			registered:         true,
+			wantRegistrations:  1,
+		},
+		{
+			name:               "missing target is registered again",
+			generation:         1,
+			observedGeneration: 1,
+			wantRegistrations:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthCheck := newTestHealthCheck("api", "default", tt.generation)
+			healthCheck.Status.ObservedGeneration = tt.observedGeneration
+			c := fake.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(healthCheck).
+				WithStatusSubresource(healthCheck).
+				Build()
+			registry := &fakeRegistry{}
+			if tt.registered {
+				registry.RegisterHealthTarget("default/api", nil)
+			}
+			r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			got := registry.registrations()
+			if len(got) != tt.wantRegistrations {
+				t.Fatalf("Reconcile() registrations = %v, want %v", len(got), tt.wantRegistrations)
+			}
+			latest := got[len(got)-1]
+			if latest.name != "default/api" {
+				t.Errorf("Reconcile() registered target = %v, want default/api", latest.name)
+			}
+			if len(latest.checks) > 0 && latest.checks[0].Name != "api-root" {
+				t.Errorf("Reconcile() registered check = %v, want api-root", latest.checks[0].Name)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReconciler_Maintenance(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "annotated", annotations: map[string]string{maintenanceAnnotation: "true"}, want: true},
+		{name: "not annotated", want: false},
+		{name: "other value", annotations: map[string]string{maintenanceAnnotation: "yes"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthCheck := newTestHealthCheck("api", "default", 1)
+			healthCheck.Annotations = tt.annotations
+			c := fake.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(healthCheck).
+				WithStatusSubresource(healthCheck).
+				Build()
+			registry := &fakeRegistry{}
+			r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if got := registry.maintenance["default/api"]; got != tt.want {
+				t.Errorf("Reconcile() maintenance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReconciler_ValidatesChecks(t *testing.T) {
+	tests := []struct {
+		name              string
+		protocol          string
+		url               string
+		interval          *time.Duration
+		timeout           *time.Duration
+		registered        bool
+		wantStatus        metav1.ConditionStatus
+		wantReason        string
+		wantRegistrations int
+		wantUnregistered  int
+	}{
+		{
+			name:              "valid http check",
+			protocol:          "http",
+			url:               "https://status.example.com/healthz",
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        reasonRegistered,
+			wantRegistrations: 1,
+		},
+		{
+			name:              "valid tcp check",
+			protocol:          "tcp",
+			url:               "tcp://db.example.com:5432",
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        reasonRegistered,
+			wantRegistrations: 1,
+		},
+		{
+			name:       "unparseable url",
+			protocol:   "http",
+			url:        "http://[::1",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "unsupported scheme",
+			protocol:   "http",
+			url:        "ftp://files.example.com/health",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "missing host",
+			protocol:   "https",
+			url:        "https:///healthz",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "tcp url without port",
+			protocol:   "tcp",
+			url:        "db.example.com",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:              "valid grpc check",
+			protocol:          "grpc",
+			url:               "grpc://grpc.example.com:443/api.v1.Users",
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        reasonRegistered,
+			wantRegistrations: 1,
+		},
+		{
+			name:       "grpc url without scheme",
+			protocol:   "grpc",
+			url:        "grpc.example.com:443",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:              "replicas check in its own namespace",
+			protocol:          "replicas",
+			url:               "replicas://deployment/default/api",
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        reasonRegistered,
+			wantRegistrations: 1,
+		},
+		{
+			name:       "replicas check in another namespace",
+			protocol:   "replicas",
+			url:        "replicas://deployment/kube-system/coredns",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "zero interval",
+			protocol:   "http",
+			url:        "http://api.default.svc.cluster.local/healthz",
+			interval:   ptr.To(time.Duration(0)),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "negative interval",
+			protocol:   "http",
+			url:        "http://api.default.svc.cluster.local/healthz",
+			interval:   ptr.To(-time.Second),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "zero timeout",
+			protocol:   "http",
+			url:        "http://api.default.svc.cluster.local/healthz",
+			timeout:    ptr.To(time.Duration(0)),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "negative timeout",
+			protocol:   "http",
+			url:        "http://api.default.svc.cluster.local/healthz",
+			timeout:    ptr.To(-time.Second),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:       "unsupported protocol",
+			protocol:   "sctp",
+			url:        "sctp://dns.example.com:53",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonInvalidSpec,
+		},
+		{
+			name:              "invalid spec removes an existing registration",
+			protocol:          "http",
+			url:               "ftp://files.example.com/health",
+			registered:        true,
+			wantStatus:        metav1.ConditionFalse,
+			wantReason:        reasonInvalidSpec,
+			wantRegistrations: 1,
+			wantUnregistered:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthCheck := newTestHealthCheck("api", "default", 1)
+			healthCheck.Spec.Checks[0].Protocol = tt.protocol
+			healthCheck.Spec.Checks[0].URL = tt.url
+			if tt.interval != nil {
+				healthCheck.Spec.Checks[0].Interval = metav1.Duration{Duration: *tt.interval}
+			}
+			if tt.timeout != nil {
+				healthCheck.Spec.Checks[0].Timeout = metav1.Duration{Duration: *tt.timeout}
+			}
+			c := fake.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(healthCheck).
+				WithStatusSubresource(healthCheck).
+				Build()
+			registry := &fakeRegistry{}
+			if tt.registered {
+				registry.RegisterHealthTarget("default/api", nil)
+			}
+			r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			if got := len(registry.registrations()); got != tt.wantRegistrations {
+				t.Errorf("Reconcile() registrations = %v, want %v", got, tt.wantRegistrations)
+			}
+			if got := len(registry.unregistered); got != tt.wantUnregistered {
+				t.Errorf("Reconcile() unregistrations = %v, want %v", got, tt.wantUnregistered)
+			}
+
+			var got healthv1alpha1.HealthCheck
+			if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, conditionAvailable)
+			if condition == nil {
+				t.Fatalf("Status.Conditions missing %s", conditionAvailable)
+			}
+			if condition.Status != tt.wantStatus {
+				t.Errorf("condition status = %v, want %v", condition.Status, tt.wantStatus)
+			}
+			if condition.Reason != tt.wantReason {
+				t.Errorf("condition reason = %v, want %v", condition.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReconciler_ServiceSelector(t *testing.T) {
+	newLabeledService := func(name string, tier string) *corev1.Service {
+		service := newTestService(name, "default", map[string]string{"app": name},
+			corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+		service.Labels = map[string]string{"tier": tier}
+		return &service
+	}
+	newProbedPod := func(app string) *corev1.Pod {
+		pod := newTestPod(app+"-0", "default", map[string]string{"app": app}, newHTTPProbeContainer("app", 8080, "/healthz"))
+		return &pod
+	}
+
+	healthCheck := newTestHealthCheck("frontends", "default", 1)
+	healthCheck.Spec.Checks = nil
+	healthCheck.Spec.ServiceSelector = &healthv1alpha1.ServiceSelector{
+		LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+	}
+	healthCheck.Status.SelectedServices = []string{"default/retired"}
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(
+			healthCheck,
+			newLabeledService("web", "web"), newProbedPod("web"),
+			newLabeledService("shop", "web"), newProbedPod("shop"),
+			newLabeledService("db", "data"), newProbedPod("db"),
+		).
+		WithStatusSubresource(healthCheck).
+		Build()
+	registry := &fakeRegistry{}
+	r := &HealthCheckReconciler{Client: c, HealthChecker: registry}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "frontends"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var registered []string
+	for _, registration := range registry.registrations() {
+		registered = append(registered, registration.name)
+	}
+	slices.Sort(registered)
+	if want := []string{"default/shop@default.frontends", "default/web@default.frontends"}; !slices.Equal(registered, want) {
+		t.Errorf("Reconcile() registered = %v, want %v", registered, want)
+	}
+	if want := []string{"default/retired@default.frontends"}; !slices.Equal(registry.unregistered, want) {
+		t.Errorf("Reconcile() unregistered = %v, want %v", registry.unregistered, want)
+	}
+
+	// A status refresh with unchanged checks must not re-register and undo overrides
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := len(registry.registrations()); got != len(registered) {
+		t.Errorf("Reconcile() registrations after refresh = %v, want %v", got, len(registered))
+	}
+
+	var got healthv1alpha1.HealthCheck
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := []string{"default/shop", "default/web"}; !slices.Equal(got.Status.SelectedServices, want) {
+		t.Errorf("Status.SelectedServices = %v, want %v", got.Status.SelectedServices, want)
+	}
+}
+
+func TestServiceToHealthChecks(t *testing.T) {
+	selecting := newTestHealthCheck("frontends", "default", 1)
+	selecting.Spec.ServiceSelector = &healthv1alpha1.ServiceSelector{
+		LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+	}
+	otherNamespace := newTestHealthCheck("frontends", "shop", 1)
+	otherNamespace.Spec.ServiceSelector = selecting.Spec.ServiceSelector.DeepCopy()
+	explicit := newTestHealthCheck("api", "default", 1)
+
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(selecting, otherNamespace, explicit).
+		Build()
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []reconcile.Request
+	}{
+		{
+			name:   "matching service",
+			labels: map[string]string{"tier": "web"},
+			want:   []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "frontends"}}},
+		},
+		{
+			name:   "non-matching service",
+			labels: map[string]string{"tier": "data"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("web", "default", nil)
+			service.Labels = tt.labels
+
+			got := serviceToHealthChecks(c)(context.Background(), &service)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serviceToHealthChecks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateChecks_Names(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []string
+		wantErr bool
+	}{
+		{name: "unique names", checks: []string{"liveness", "readiness"}},
+		{name: "duplicate names", checks: []string{"liveness", "liveness"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := make([]healthcheck.CheckConfig, 0, len(tt.checks))
+			for _, name := range tt.checks {
+				checks = append(checks, healthcheck.CheckConfig{
+					Name:     name,
+					URL:      "http://api.default.svc:8080/" + name,
+					Protocol: "http",
+					Interval: 15 * time.Second,
+					Timeout:  time.Second,
+				})
+			}
+			if err := validateChecks("default", checks); (err != nil) != tt.wantErr {
+				t.Errorf("validateChecks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCheck_BodyExpectations(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   healthcheck.CheckConfig
+		wantErr bool
+	}{
+		{
+			name:  "json path and value",
+			check: healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", ExpectJSONPath: "status", ExpectJSONValue: "ok"},
+		},
+		{
+			name:    "json value without path",
+			check:   healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", ExpectJSONValue: "ok"},
+			wantErr: true,
+		},
+		{
+			name:    "body expectation on tcp",
+			check:   healthcheck.CheckConfig{Protocol: "tcp", URL: "tcp://db:5432", ExpectBodyContains: "ok"},
+			wantErr: true,
+		},
+		{
+			name:  "udp with payload",
+			check: healthcheck.CheckConfig{Protocol: "udp", URL: "udp://dns:53", Payload: "ping"},
+		},
+		{
+			name:    "udp without port",
+			check:   healthcheck.CheckConfig{Protocol: "udp", URL: "udp://dns"},
+			wantErr: true,
+		},
+		{
+			name:    "payload on http",
+			check:   healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", Payload: "ping"},
+			wantErr: true,
+		},
+		{
+			name:  "icmp host",
+			check: healthcheck.CheckConfig{Protocol: "icmp", URL: "icmp://gateway.example.com"},
+		},
+		{
+			name:    "icmp without scheme",
+			check:   healthcheck.CheckConfig{Protocol: "icmp", URL: "gateway.example.com"},
+			wantErr: true,
+		},
+		{
+			name:  "replicas workload",
+			check: healthcheck.CheckConfig{Protocol: "replicas", URL: "replicas://deployment/default/api"},
+		},
+		{
+			name:    "replicas workload in another namespace",
+			check:   healthcheck.CheckConfig{Protocol: "replicas", URL: "replicas://deployment/kube-system/coredns"},
+			wantErr: true,
+		},
+		{
+			name:    "replicas without namespace",
+			check:   healthcheck.CheckConfig{Protocol: "replicas", URL: "replicas://deployment/api"},
+			wantErr: true,
+		},
+		{
+			name:    "icmp with path",
+			check:   healthcheck.CheckConfig{Protocol: "icmp", URL: "icmp://gateway.example.com/health"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCheck("default", tt.check); (err != nil) != tt.wantErr {
+				t.Errorf("validateCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCheck_RequestBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   healthcheck.CheckConfig
+		wantErr bool
+	}{
+		{
+			name:  "post with body",
+			check: healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", Method: "POST", RequestBody: `{}`, ContentType: "application/json"},
+		},
+		{
+			name:    "get with body",
+			check:   healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", RequestBody: `{}`},
+			wantErr: true,
+		},
+		{
+			name:    "content type without body",
+			check:   healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", Method: "POST", ContentType: "application/json"},
+			wantErr: true,
+		},
+		{
+			name:    "oversized body",
+			check:   healthcheck.CheckConfig{Protocol: "http", URL: "http://api/healthz", Method: "PUT", RequestBody: strings.Repeat("a", healthcheck.MaxRequestBodyBytes+1)},
+			wantErr: true,
+		},
+		{
+			name:  "grpc service name",
+			check: healthcheck.CheckConfig{Protocol: "grpc", URL: "grpc://api:9090", RequestBody: "api.v1.Health"},
+		},
+		{
+			name:    "method on tcp",
+			check:   healthcheck.CheckConfig{Protocol: "tcp", URL: "tcp://db:5432", Method: "POST"},
+			wantErr: true,
+		},
+		{
+			name:    "body on udp",
+			check:   healthcheck.CheckConfig{Protocol: "udp", URL: "udp://dns:53", RequestBody: "ping"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCheck("default", tt.check); (err != nil) != tt.wantErr {
+				t.Errorf("validateCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
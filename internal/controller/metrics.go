@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "constellation_reconcile_total",
+		Help: "Total number of reconciles handled per controller.",
+	}, []string{"controller"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "constellation_reconcile_duration_seconds",
+		Help:    "Duration of reconciles per controller.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileTotal, reconcileDuration)
+}
+
+// observeReconcile records a finished reconcile. Call it deferred at the top of
+// Reconcile so the start time is captured on entry.
+func observeReconcile(controller string, start time.Time) {
+	reconcileTotal.WithLabelValues(controller).Inc()
+	reconcileDuration.WithLabelValues(controller).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func reconcileDurationCount(t *testing.T, controller string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := reconcileDuration.WithLabelValues(controller).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestReconcileMetrics(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+
+	tests := []struct {
+		name       string
+		controller string
+		reconcile  func(ctrl.Request) error
+		reconciles int
+	}{
+		{
+			name:       "service reconciles are counted",
+			controller: "service",
+			reconcile: func(req ctrl.Request) error {
+				r := &ServiceReconciler{
+					Client:        fake.NewClientBuilder().WithObjects(&service, &pod).Build(),
+					HealthChecker: &fakeRegistry{},
+					registrations: newDebouncer(0),
+				}
+				_, err := r.Reconcile(context.Background(), req)
+				return err
+			},
+			reconciles: 3,
+		},
+		{
+			name:       "failed lookups are still counted",
+			controller: "healthcheck",
+			reconcile: func(req ctrl.Request) error {
+				r := &HealthCheckReconciler{
+					Client:        fake.NewClientBuilder().WithScheme(newTestScheme(t)).Build(),
+					HealthChecker: &fakeRegistry{},
+				}
+				_, err := r.Reconcile(context.Background(), req)
+				return err
+			},
+			reconciles: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beforeTotal := testutil.ToFloat64(reconcileTotal.WithLabelValues(tt.controller))
+			beforeCount := reconcileDurationCount(t, tt.controller)
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			for range tt.reconciles {
+				if err := tt.reconcile(req); err != nil {
+					t.Fatalf("Reconcile() error = %v", err)
+				}
+			}
+
+			gotTotal := testutil.ToFloat64(reconcileTotal.WithLabelValues(tt.controller)) - beforeTotal
+			if gotTotal != float64(tt.reconciles) {
+				t.Errorf("constellation_reconcile_total{controller=%q} increased by %v, want %v",
+					tt.controller, gotTotal, tt.reconciles)
+			}
+			gotCount := reconcileDurationCount(t, tt.controller) - beforeCount
+			if gotCount != uint64(tt.reconciles) {
+				t.Errorf("constellation_reconcile_duration_seconds{controller=%q} observations = %v, want %v",
+					tt.controller, gotCount, tt.reconciles)
+			}
+		})
+	}
+}
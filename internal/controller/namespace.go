@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// includeAnnotation switches discovery to allowlist mode: once any namespace
+// carries it, only namespaces annotated with "true" are discovered.
+const includeAnnotation = "constellation.kyledev.co/include"
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// namespaceExcluded reports whether discovery should skip a namespace. The
+// ignore annotation always wins over the include annotation.
+func namespaceExcluded(namespace corev1.Namespace, namespaces []corev1.Namespace) bool {
+	if shouldIgnoreResource(namespace.Annotations) {
+		return true
+	}
+	if !includeModeEnabled(namespaces) {
+		return false
+	}
+	return namespace.Annotations[includeAnnotation] != "true"
+}
+
+func includeModeEnabled(namespaces []corev1.Namespace) bool {
+	for _, namespace := range namespaces {
+		if _, exists := namespace.Annotations[includeAnnotation]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// isNamespaceExcluded looks up a namespace and applies namespaceExcluded to it
+func isNamespaceExcluded(ctx context.Context, c client.Reader, name string) (bool, error) {
+	var namespaces corev1.NamespaceList
+	if err := c.List(ctx, &namespaces); err != nil {
+		return false, err
+	}
+
+	for _, namespace := range namespaces.Items {
+		if namespace.Name == name {
+			return namespaceExcluded(namespace, namespaces.Items), nil
+		}
+	}
+	return false, nil
+}
+
+// namespaceToServices reconciles every service when a namespace changes, since
+// adding an include annotation anywhere changes which namespaces are discovered
+func namespaceToServices(c client.Reader) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, _ client.Object) []reconcile.Request {
+		var services corev1.ServiceList
+		if err := c.List(ctx, &services); err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(services.Items))
+		for _, service := range services.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: service.Namespace, Name: service.Name},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestNamespace(name string, annotations map[string]string) corev1.Namespace {
+	return corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+	}
+}
+
+func TestNamespaceExcluded(t *testing.T) {
+	ignored := map[string]string{ignoreAnnotation: "true"}
+	included := map[string]string{includeAnnotation: "true"}
+	both := map[string]string{ignoreAnnotation: "true", includeAnnotation: "true"}
+
+	tests := []struct {
+		name       string
+		namespace  corev1.Namespace
+		namespaces []corev1.Namespace
+		want       bool
+	}{
+		{
+			name:       "plain namespace is discovered",
+			namespace:  newTestNamespace("apps", nil),
+			namespaces: []corev1.Namespace{newTestNamespace("apps", nil)},
+			want:       false,
+		},
+		{
+			name:       "ignored namespace",
+			namespace:  newTestNamespace("apps", ignored),
+			namespaces: []corev1.Namespace{newTestNamespace("apps", ignored)},
+			want:       true,
+		},
+		{
+			name:      "included namespace in include-only mode",
+			namespace: newTestNamespace("apps", included),
+			namespaces: []corev1.Namespace{
+				newTestNamespace("apps", included),
+				newTestNamespace("other", nil),
+			},
+			want: false,
+		},
+		{
+			name:      "unannotated namespace in include-only mode",
+			namespace: newTestNamespace("other", nil),
+			namespaces: []corev1.Namespace{
+				newTestNamespace("apps", included),
+				newTestNamespace("other", nil),
+			},
+			want: true,
+		},
+		{
+			name:       "ignore wins over include",
+			namespace:  newTestNamespace("apps", both),
+			namespaces: []corev1.Namespace{newTestNamespace("apps", both)},
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namespaceExcluded(tt.namespace, tt.namespaces); got != tt.want {
+				t.Errorf("namespaceExcluded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceReconciler_ExcludedNamespace(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+
+	tests := []struct {
+		name              string
+		namespaces        []corev1.Namespace
+		wantRegistrations int
+		wantUnregistered  int
+	}{
+		{
+			name:              "discovered namespace registers",
+			namespaces:        []corev1.Namespace{newTestNamespace("default", nil)},
+			wantRegistrations: 1,
+		},
+		{
+			name: "ignored namespace unregisters",
+			namespaces: []corev1.Namespace{
+				newTestNamespace("default", map[string]string{ignoreAnnotation: "true"}),
+			},
+			wantUnregistered: 1,
+		},
+		{
+			name: "namespace outside include-only mode unregisters",
+			namespaces: []corev1.Namespace{
+				newTestNamespace("default", nil),
+				newTestNamespace("payments", map[string]string{includeAnnotation: "true"}),
+			},
+			wantUnregistered: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("api", "default", selector,
+				corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+			pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+
+			builder := fake.NewClientBuilder().WithObjects(&service, &pod)
+			for i := range tt.namespaces {
+				builder = builder.WithObjects(&tt.namespaces[i])
+			}
+			registry := &fakeRegistry{}
+			r := &ServiceReconciler{
+				Client:        builder.Build(),
+				HealthChecker: registry,
+				registrations: newDebouncer(0),
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			if got := len(registry.registrations()); got != tt.wantRegistrations {
+				t.Errorf("Reconcile() registrations = %v, want %v", got, tt.wantRegistrations)
+			}
+			if got := len(registry.unregistered); got != tt.wantUnregistered {
+				t.Errorf("Reconcile() unregistrations = %v, want %v", got, tt.wantUnregistered)
+			}
+		})
+	}
+}
+
+func TestNamespaceToServices(t *testing.T) {
+	first := newTestService("api", "default", nil)
+	second := newTestService("web", "payments", nil)
+	c := fake.NewClientBuilder().WithObjects(&first, &second).Build()
+
+	namespace := newTestNamespace("payments", nil)
+	got := namespaceToServices(c)(context.Background(), &namespace)
+	if len(got) != 2 {
+		t.Errorf("namespaceToServices() requests = %v, want 2", got)
+	}
+}
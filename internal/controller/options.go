@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kdwils/constellation/internal/healthcheck"
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// HealthTargetRegistry is the part of the HealthChecker used by the reconcilers
+type HealthTargetRegistry interface {
+	RegisterHealthTarget(name string, checks []healthcheck.CheckConfig)
+	UnregisterHealthTarget(name string)
+	SetTargetMetadata(name string, metadata healthcheck.TargetMetadata)
+}
+
+// HealthTargetMonitor is a HealthTargetRegistry that also reports the results of registered targets
+type HealthTargetMonitor interface {
+	HealthTargetRegistry
+	SetMaintenance(name string, enabled bool)
+	IsRegistered(name string) bool
+	GetHealthData(name string) (*types.ServiceHealthInfo, bool)
+}
+
+// DiscoveryOptions configures how reconcilers derive and register health checks
+type DiscoveryOptions struct {
+	// RegistrationDebounce delays registration so that only the last config
+	// seen within the window is registered. Zero registers immediately.
+	RegistrationDebounce time.Duration
+	// IncludeUnknownPods keeps probing pods in the Unknown phase, usually caused by
+	// an unreachable node. They are reported as service warnings either way.
+	IncludeUnknownPods bool
+	// CompletedPodGrace keeps Succeeded and Failed pods for this long after they
+	// finish, so a Job's service does not flicker while its pod terminates. They
+	// are reported as service warnings. Zero excludes them immediately.
+	CompletedPodGrace time.Duration
+	// MinInterval and MaxInterval clamp the interval derived from a probe's
+	// period, so a 1s probe does not hammer the service. Zero leaves that side unbounded.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// ClusterDomain is the DNS suffix of in-cluster service hostnames
+	ClusterDomain string
+	// DefaultCheckPath synthesizes an HTTP GET check of this path on the first
+	// service port for services whose pods declare no usable probe. Empty
+	// leaves them unmonitored unless annotated.
+	DefaultCheckPath string
+}
+
+const (
+	DefaultMinInterval   = 5 * time.Second
+	DefaultMaxInterval   = 5 * time.Minute
+	DefaultClusterDomain = "cluster.local"
+
+	// defaultCheckInterval and defaultCheckTimeout are what Kubernetes applies
+	// to a probe that sets neither, used for synthesized checks
+	defaultCheckInterval = 10 * time.Second
+	defaultCheckTimeout  = time.Second
+)
+
+type DiscoveryOpt func(*DiscoveryOptions)
+
+func WithRegistrationDebounce(window time.Duration) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		o.RegistrationDebounce = window
+	}
+}
+
+func WithIncludeUnknownPods(include bool) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		o.IncludeUnknownPods = include
+	}
+}
+
+func WithCompletedPodGrace(grace time.Duration) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		o.CompletedPodGrace = grace
+	}
+}
+
+// WithIntervalBounds clamps derived check intervals into [minInterval, maxInterval]
+func WithIntervalBounds(minInterval, maxInterval time.Duration) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		o.MinInterval = minInterval
+		o.MaxInterval = maxInterval
+	}
+}
+
+// WithClusterDomain sets the DNS suffix used to build check URLs, e.g. for
+// clusters not using cluster.local. Empty keeps the default.
+func WithClusterDomain(domain string) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		if domain != "" {
+			o.ClusterDomain = strings.Trim(domain, ".")
+		}
+	}
+}
+
+// WithDefaultCheckPath checks path on services without probes, as if every
+// service carried the healthcheck-path annotation
+func WithDefaultCheckPath(path string) DiscoveryOpt {
+	return func(o *DiscoveryOptions) {
+		o.DefaultCheckPath = strings.TrimSpace(path)
+	}
+}
+
+func newDiscoveryOptions(opts ...DiscoveryOpt) DiscoveryOptions {
+	o := DiscoveryOptions{
+		MinInterval:   DefaultMinInterval,
+		MaxInterval:   DefaultMaxInterval,
+		ClusterDomain: DefaultClusterDomain,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// clampInterval bounds interval by the configured minimum and maximum and
+// reports whether it had to be changed
+func (o DiscoveryOptions) clampInterval(interval time.Duration) (time.Duration, bool) {
+	if o.MinInterval > 0 && interval < o.MinInterval {
+		return o.MinInterval, true
+	}
+	if o.MaxInterval > 0 && interval > o.MaxInterval {
+		return o.MaxInterval, true
+	}
+	return interval, false
+}
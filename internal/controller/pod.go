@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// podToServices reconciles the services a changed pod backs, so their checks
+// and metadata are only ever derived by the service reconciler. The changed
+// pod is looked up alongside the listed ones, so a deleted pod still maps to
+// the services it was backing.
+func podToServices(c client.Reader) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return nil
+		}
+
+		var services corev1.ServiceList
+		if err := c.List(ctx, &services, client.InNamespace(pod.Namespace)); err != nil {
+			return nil
+		}
+		var pods corev1.PodList
+		if err := c.List(ctx, &pods, client.InNamespace(pod.Namespace)); err != nil {
+			return nil
+		}
+		var slices discoveryv1.EndpointSliceList
+		if err := c.List(ctx, &slices, client.InNamespace(pod.Namespace)); err != nil {
+			return nil
+		}
+		slicesByService := groupEndpointSlicesByService(slices.Items)
+
+		candidates := pods.Items
+		if !isBackendPod(pod.Name, candidates) {
+			candidates = append(candidates, *pod)
+		}
+
+		var requests []reconcile.Request
+		for _, service := range services.Items {
+			if !isBackendPod(pod.Name, findBackendPods(service, slicesByService[service.Name], candidates)) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: service.Namespace, Name: service.Name},
+			})
+		}
+		return requests
+	}
+}
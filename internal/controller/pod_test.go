@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestPodToServices(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	api := newTestService("api", "default", selector)
+	external := newTestService("external", "default", nil)
+	other := newTestService("web", "default", map[string]string{"app": "web"})
+	pod := newTestPod("api-0", "default", selector)
+	legacy := newTestPod("legacy-0", "default", map[string]string{"app": "legacy"})
+	slice := newTestEndpointSlice("external-abc", "default", "external", newTestEndpoint("legacy-0", ptr.To(true)))
+
+	c := fake.NewClientBuilder().WithObjects(&api, &external, &other, &pod, &legacy, &slice).Build()
+	deleted := newTestPod("api-1", "default", selector)
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want []reconcile.Request
+	}{
+		{
+			name: "pod matched by selector",
+			pod:  pod,
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}},
+		},
+		{
+			name: "pod listed by a selectorless service's slice",
+			pod:  legacy,
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "external"}}},
+		},
+		{
+			name: "deleted pod maps to the services it backed",
+			pod:  deleted,
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}}},
+		},
+		{
+			name: "pod backing nothing",
+			pod:  newTestPod("batch-0", "default", map[string]string{"app": "batch"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := podToServices(c)(context.Background(), &tt.pod)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("podToServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
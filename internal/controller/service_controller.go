@@ -3,32 +3,60 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/kdwils/constellation/internal/healthcheck"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-const ignoreAnnotation = "constellation.kyledev.co/ignore"
+const (
+	ignoreAnnotation = "constellation.kyledev.co/ignore"
+	// groupAnnotation assigns a service to a group that its health is aggregated under.
+	// Slashes nest groups, e.g. platform/observability.
+	groupAnnotation = "constellation.kyledev.co/group"
+	// checkHostAnnotation replaces the in-cluster hostname in the service's check
+	// URLs, e.g. when the controller runs outside the cluster
+	checkHostAnnotation = "constellation.kyledev.co/check-host"
+	// maintenanceAnnotation set to "true" on a Service or HealthCheck reports
+	// failures as maintenance and suppresses alerts while it is present
+	maintenanceAnnotation = "constellation.kyledev.co/maintenance"
+	// maxConcurrentChecksAnnotation caps how many of the service's checks run
+	// at once, for services with many pods behind a single instance
+	maxConcurrentChecksAnnotation = "constellation.kyledev.co/max-concurrent-checks"
+	// defaultCheckPathAnnotation opts a service whose pods have no probes into
+	// an HTTP GET check of this path on its first TCP port
+	defaultCheckPathAnnotation = "constellation.kyledev.co/healthcheck-path"
+)
 
 // ServiceReconciler reconciles Service objects
 type ServiceReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	HealthChecker *healthcheck.HealthChecker
+	HealthChecker HealthTargetRegistry
+	options       DiscoveryOptions
+	registrations *debouncer
 }
 
 // NewServiceReconciler creates a new ServiceReconciler
-func NewServiceReconciler(mgr ctrl.Manager, healthChecker *healthcheck.HealthChecker) *ServiceReconciler {
+func NewServiceReconciler(mgr ctrl.Manager, healthChecker HealthTargetRegistry, opts ...DiscoveryOpt) *ServiceReconciler {
+	options := newDiscoveryOptions(opts...)
 	return &ServiceReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		HealthChecker: healthChecker,
+		options:       options,
+		registrations: newDebouncer(options.RegistrationDebounce),
 	}
 }
 
@@ -37,6 +65,8 @@ func NewServiceReconciler(mgr ctrl.Manager, healthChecker *healthcheck.HealthChe
 
 // Reconcile handles Service events
 func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer observeReconcile("service", time.Now())
+
 	logger := log.FromContext(ctx)
 
 	var service corev1.Service
@@ -44,6 +74,7 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if client.IgnoreNotFound(err) == nil {
 			serviceKey := fmt.Sprintf("%s/%s", req.Namespace, req.Name)
 			logger.Info("service deleted, unregistering health check", "service", serviceKey)
+			r.registrations.Cancel(serviceKey)
 			r.HealthChecker.UnregisterHealthTarget(serviceKey)
 			return ctrl.Result{}, nil
 		}
@@ -55,27 +86,106 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	var pods corev1.PodList
-	if err := r.List(ctx, &pods, client.InNamespace(req.Namespace)); err != nil {
-		logger.Error(err, "failed to list pods")
+	excluded, err := isNamespaceExcluded(ctx, r.Client, req.Namespace)
+	if err != nil {
+		logger.Error(err, "failed to list namespaces")
 		return ctrl.Result{}, err
 	}
+	if excluded {
+		serviceKey := fmt.Sprintf("%s/%s", req.Namespace, req.Name)
+		r.registrations.Cancel(serviceKey)
+		r.HealthChecker.UnregisterHealthTarget(serviceKey)
+		return ctrl.Result{}, nil
+	}
 
-	checks := extractHealthChecksFromPods(service, pods.Items)
-	if len(checks) > 0 {
-		serviceKey := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+	checks, warnings, requeueAfter, err := discoverServiceChecks(ctx, r.Client, service, r.options)
+	if err != nil {
+		logger.Error(err, "failed to discover service health checks")
+		return ctrl.Result{}, err
+	}
+
+	var services corev1.ServiceList
+	if err := r.List(ctx, &services); err != nil {
+		logger.Error(err, "failed to list services")
+		return ctrl.Result{}, err
+	}
+
+	serviceKey := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+	for _, warning := range warnings {
+		logger.Info("skipping probe", "service", serviceKey, "reason", warning)
+	}
+	conflicts := findPortConflicts(service, services.Items)
+	for _, conflict := range conflicts {
+		logger.Info("port conflict", "service", serviceKey, "reason", conflict)
+	}
+	warnings = append(warnings, conflicts...)
+	r.registrations.Do(serviceKey, func() {
+		r.HealthChecker.SetTargetMetadata(serviceKey, targetMetadata(service, warnings))
+		if len(checks) == 0 {
+			return
+		}
 		logger.Info("registering discovered service health check", "identifier", serviceKey, "checks", len(checks))
 		r.HealthChecker.RegisterHealthTarget(serviceKey, checks)
+	})
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// discoverServiceChecks derives health checks from the probes of the pods
+// backing a service. requeueAfter is when the grace period of the first
+// completed pod still being checked runs out, or zero if there is none.
+func discoverServiceChecks(
+	ctx context.Context,
+	c client.Reader,
+	service corev1.Service,
+	options DiscoveryOptions,
+) (checks []healthcheck.CheckConfig, warnings []string, requeueAfter time.Duration, err error) {
+	// ExternalName services have no selector or endpoints, only a default
+	// check can apply to them
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		checks, warnings := extractHealthChecksFromPods(service, nil, options)
+		return checks, warnings, 0, nil
+	}
+
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(service.Namespace)); err != nil {
+		return nil, nil, 0, fmt.Errorf("listing pods: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	var slices discoveryv1.EndpointSliceList
+	if err := c.List(ctx, &slices, client.InNamespace(service.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return nil, nil, 0, fmt.Errorf("listing endpoint slices: %w", err)
+	}
+
+	backends := findBackendPods(service, slices.Items, pods.Items)
+	checks, warnings = extractHealthChecksFromPods(service, backends, options)
+	return checks, warnings, completedPodGraceRemaining(backends, options, time.Now()), nil
+}
+
+// probeKey identifies a probe independently of the pod it was read from, so
+// replicas of the same container collapse into one check
+type probeKey struct {
+	container string
+	port      int32
+	url       string
 }
 
-// extractHealthChecksFromPods extracts health check configurations from pod liveness probes
-func extractHealthChecksFromPods(service corev1.Service, pods []corev1.Pod) []healthcheck.CheckConfig {
+// extractHealthChecksFromPods extracts health check configurations from the
+// liveness probes of the pods backing a service. Probes on container ports the
+// service does not expose, and pods whose node is not reporting, are returned as
+// warnings instead of being dropped silently.
+func extractHealthChecksFromPods(
+	service corev1.Service,
+	pods []corev1.Pod,
+	options DiscoveryOptions,
+) ([]healthcheck.CheckConfig, []string) {
 	checkName := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
 	var checks []healthcheck.CheckConfig
-	seenURLs := make(map[string]bool)
+	var warnings []string
+	seen := make(map[probeKey]bool)
+	unmapped := make(map[probeKey]bool)
+	now := time.Now()
 
 	for _, pod := range pods {
 		if shouldIgnoreResource(pod.Annotations) {
@@ -84,31 +194,31 @@ func extractHealthChecksFromPods(service corev1.Service, pods []corev1.Pod) []he
 		if pod.Namespace != service.Namespace {
 			continue
 		}
-		if !labelsMatch(service.Spec.Selector, pod.Labels) {
-			continue
+		if pod.Status.Phase == corev1.PodUnknown {
+			warnings = append(warnings, fmt.Sprintf(
+				"pod %q is in phase Unknown, its node may be unreachable", pod.Name))
 		}
-		if !shouldIncludePod(pod) {
+		if !shouldIncludePod(pod, options, now) {
 			continue
 		}
+		if isCompleted(pod) {
+			warnings = append(warnings, fmt.Sprintf("pod %q completed with phase %s", pod.Name, pod.Status.Phase))
+		}
 
 		for _, container := range pod.Spec.Containers {
-			if container.LivenessProbe == nil {
-				continue
-			}
-			if container.LivenessProbe.HTTPGet == nil {
+			probe, probeType := selectProbe(container)
+			if probe == nil {
 				continue
 			}
 
-			probe := container.LivenessProbe
-			httpGet := probe.HTTPGet
-
 			if probe.PeriodSeconds == 0 || probe.TimeoutSeconds == 0 {
 				continue
 			}
 
-			containerPort := httpGet.Port.IntVal
+			port := probePort(probe)
+			containerPort := port.IntVal
 			if containerPort == 0 {
-				containerPort = resolveNamedPort(httpGet.Port.StrVal, container.Ports)
+				containerPort = resolveNamedPort(port.StrVal, container.Ports)
 			}
 			if containerPort == 0 {
 				continue
@@ -116,28 +226,171 @@ func extractHealthChecksFromPods(service corev1.Service, pods []corev1.Pod) []he
 
 			servicePort := findServicePortForContainer(service, containerPort)
 			if servicePort == 0 {
+				key := probeKey{container: container.Name, port: containerPort}
+				if !unmapped[key] {
+					unmapped[key] = true
+					warnings = append(warnings, fmt.Sprintf(
+						"%s probe for container %q targets port %d, which is not exposed by the service",
+						probeType, container.Name, containerPort))
+				}
 				continue
 			}
 
-			scheme := strings.ToLower(string(httpGet.Scheme))
-			url := fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d%s", scheme, service.Name, service.Namespace, servicePort, httpGet.Path)
+			host := checkHost(service, options)
+			scheme := "tcp"
+			checkURL := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(servicePort)))
+			if probe.HTTPGet != nil {
+				var err error
+				scheme = strings.ToLower(string(probe.HTTPGet.Scheme))
+				checkURL, err = buildProbeURL(scheme, host, servicePort, probe.HTTPGet.Path)
+				if err != nil {
+					continue
+				}
+			}
+			if probe.GRPC != nil {
+				scheme = "grpc"
+				checkURL = buildGRPCURL(host, servicePort, probe.GRPC.Service)
+			}
 
-			if seenURLs[url] {
+			key := probeKey{container: container.Name, port: servicePort, url: checkURL}
+			if seen[key] {
 				continue
 			}
-			seenURLs[url] = true
+			seen[key] = true
+
+			period := time.Duration(probe.PeriodSeconds) * time.Second
+			interval, clamped := options.clampInterval(period)
+			if clamped {
+				log.Log.WithName("discovery").V(1).Info("clamped probe interval",
+					"service", checkName, "container", container.Name, "period", period, "interval", interval)
+			}
 
 			checks = append(checks, healthcheck.CheckConfig{
 				Name:     checkName,
-				URL:      url,
-				Interval: time.Duration(probe.PeriodSeconds) * time.Second,
+				URL:      checkURL,
+				Interval: interval,
 				Timeout:  time.Duration(probe.TimeoutSeconds) * time.Second,
 				Protocol: scheme,
 			})
 		}
 	}
 
-	return checks
+	if len(checks) == 0 {
+		if check, ok := defaultCheck(service, options); ok {
+			checks = append(checks, check)
+		}
+	}
+
+	return checks, warnings
+}
+
+// defaultCheck synthesizes a check against the first TCP port of a service
+// whose pods yielded none, when the service or the discovery options name a
+// path for it
+func defaultCheck(service corev1.Service, options DiscoveryOptions) (healthcheck.CheckConfig, bool) {
+	path := strings.TrimSpace(service.Annotations[defaultCheckPathAnnotation])
+	if path == "" {
+		path = options.DefaultCheckPath
+	}
+	if path == "" {
+		return healthcheck.CheckConfig{}, false
+	}
+	port, ok := firstTCPPort(service)
+	if !ok {
+		return healthcheck.CheckConfig{}, false
+	}
+
+	checkURL, err := buildProbeURL("http", checkHost(service, options), port, path)
+	if err != nil {
+		return healthcheck.CheckConfig{}, false
+	}
+
+	interval, _ := options.clampInterval(defaultCheckInterval)
+	return healthcheck.CheckConfig{
+		Name:     fmt.Sprintf("%s/%s", service.Namespace, service.Name),
+		URL:      checkURL,
+		Interval: interval,
+		Timeout:  defaultCheckTimeout,
+		Protocol: "http",
+	}, true
+}
+
+// firstTCPPort returns the first port of a service that speaks TCP. Ports
+// without a protocol default to TCP.
+func firstTCPPort(service corev1.Service) (int32, bool) {
+	for _, port := range service.Spec.Ports {
+		if port.Protocol == "" || port.Protocol == corev1.ProtocolTCP {
+			return port.Port, true
+		}
+	}
+	return 0, false
+}
+
+// checkHost is the hostname checks of service connect to
+func checkHost(service corev1.Service, options DiscoveryOptions) string {
+	if host := strings.TrimSpace(service.Annotations[checkHostAnnotation]); host != "" {
+		return host
+	}
+	domain := options.ClusterDomain
+	if domain == "" {
+		domain = DefaultClusterDomain
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", service.Name, service.Namespace, domain)
+}
+
+// selectProbe returns the probe a check is derived from. HTTP, TCP and gRPC
+// liveness probes are preferred; a TCP or gRPC readiness probe is used when
+// there is no liveness probe to follow.
+func selectProbe(container corev1.Container) (*corev1.Probe, string) {
+	if probe := container.LivenessProbe; probe != nil && (probe.HTTPGet != nil || probe.TCPSocket != nil || probe.GRPC != nil) {
+		return probe, "liveness"
+	}
+	if probe := container.ReadinessProbe; probe != nil && (probe.TCPSocket != nil || probe.GRPC != nil) {
+		return probe, "readiness"
+	}
+	return nil, ""
+}
+
+func probePort(probe *corev1.Probe) intstr.IntOrString {
+	if probe.HTTPGet != nil {
+		return probe.HTTPGet.Port
+	}
+	if probe.GRPC != nil {
+		return intstr.FromInt32(probe.GRPC.Port)
+	}
+	return probe.TCPSocket.Port
+}
+
+// buildGRPCURL addresses the gRPC health service on the service port, with the
+// probe's service name as the path when it checks a specific service
+func buildGRPCURL(host string, port int32, service *string) string {
+	u := url.URL{Scheme: "grpc", Host: net.JoinHostPort(host, strconv.Itoa(int(port)))}
+	if service != nil && *service != "" {
+		u.Path = "/" + *service
+	}
+	return u.String()
+}
+
+// buildProbeURL joins a probe path onto the service address, adding a leading
+// slash when missing and keeping any query string intact
+func buildProbeURL(scheme, host string, port int32, probePath string) (string, error) {
+	ref, err := url.Parse(probePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid probe path %q: %w", probePath, err)
+	}
+
+	u := &url.URL{
+		Scheme:   scheme,
+		Host:     net.JoinHostPort(host, strconv.Itoa(int(port))),
+		Path:     ref.Path,
+		RawPath:  ref.RawPath,
+		RawQuery: ref.RawQuery,
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+		u.RawPath = ""
+	}
+	return u.String(), nil
 }
 
 // resolveNamedPort resolves a named port to its numeric value
@@ -169,6 +422,39 @@ func findServicePortForContainer(service corev1.Service, containerPort int32) in
 	return 0
 }
 
+// targetMetadata collects the discovery metadata reported alongside a service's checks
+func targetMetadata(service corev1.Service, warnings []string) healthcheck.TargetMetadata {
+	return healthcheck.TargetMetadata{
+		Group:               service.Annotations[groupAnnotation],
+		Warnings:            warnings,
+		Maintenance:         inMaintenance(service.Annotations),
+		MaxConcurrentChecks: maxConcurrentChecks(service.Annotations),
+		ExternalName:        externalName(service),
+	}
+}
+
+// externalName is the DNS name an ExternalName service resolves to
+func externalName(service corev1.Service) string {
+	if service.Spec.Type != corev1.ServiceTypeExternalName {
+		return ""
+	}
+	return service.Spec.ExternalName
+}
+
+// maxConcurrentChecks parses the concurrency annotation. Values that are not a
+// positive integer leave the checks unlimited.
+func maxConcurrentChecks(annotations map[string]string) int {
+	limit, err := strconv.Atoi(strings.TrimSpace(annotations[maxConcurrentChecksAnnotation]))
+	if err != nil || limit < 1 {
+		return 0
+	}
+	return limit
+}
+
+func inMaintenance(annotations map[string]string) bool {
+	return annotations[maintenanceAnnotation] == "true"
+}
+
 // shouldIgnoreResource checks if a resource should be ignored
 func shouldIgnoreResource(annotations map[string]string) bool {
 	if annotations == nil {
@@ -195,20 +481,78 @@ func labelsMatch(selector, labels map[string]string) bool {
 }
 
 // shouldIncludePod checks if a pod should be included
-func shouldIncludePod(pod corev1.Pod) bool {
-	if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
+func shouldIncludePod(pod corev1.Pod, options DiscoveryOptions, now time.Time) bool {
+	if pod.DeletionTimestamp != nil {
 		return false
 	}
-	if pod.DeletionTimestamp != nil {
+	switch pod.Status.Phase {
+	case corev1.PodRunning, corev1.PodPending:
+		return true
+	case corev1.PodUnknown:
+		return options.IncludeUnknownPods
+	case corev1.PodSucceeded, corev1.PodFailed:
+		if options.CompletedPodGrace <= 0 {
+			return false
+		}
+		finished, ok := completedAt(pod)
+		return ok && now.Sub(finished) <= options.CompletedPodGrace
+	default:
 		return false
 	}
-	return true
+}
+
+// completedPodGraceRemaining is how long until the first completed pod that is
+// still within its grace period leaves it, so the service can be reconciled
+// again to drop its checks. It is zero when no such pod exists.
+func completedPodGraceRemaining(pods []corev1.Pod, options DiscoveryOptions, now time.Time) time.Duration {
+	var remaining time.Duration
+	for _, pod := range pods {
+		if !isCompleted(pod) || !shouldIncludePod(pod, options, now) {
+			continue
+		}
+		finished, _ := completedAt(pod)
+		// Requeue just past the boundary, since a pod is still kept at exactly the grace period
+		left := finished.Add(options.CompletedPodGrace).Sub(now) + time.Second
+		if remaining == 0 || left < remaining {
+			remaining = left
+		}
+	}
+	return remaining
+}
+
+func isCompleted(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// completedAt returns when a finished pod's last container terminated, falling
+// back to when the pod stopped being ready
+func completedAt(pod corev1.Pod) (time.Time, bool) {
+	var finished time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(finished) {
+			finished = terminated.FinishedAt.Time
+		}
+	}
+	if !finished.IsZero() {
+		return finished, true
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // SetupWithManager sets up the controller with the Manager
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Service{}).
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(endpointSliceToService)).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(podToServices(mgr.GetClient()))).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceToServices(mgr.GetClient()))).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(serviceToConflictingServices(mgr.GetClient()))).
 		Named("service").
 		Complete(r)
 }
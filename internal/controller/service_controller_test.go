@@ -0,0 +1,809 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kdwils/constellation/internal/healthcheck"
+)
+
+func newTestService(name, namespace string, selector map[string]string, ports ...corev1.ServicePort) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports:    ports,
+		},
+	}
+}
+
+func newTestPod(name, namespace string, labels map[string]string, containers ...corev1.Container) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.PodSpec{Containers: containers},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func newHTTPProbeContainer(name string, port int32, path string) corev1.Container {
+	return corev1.Container{
+		Name:  name,
+		Ports: []corev1.ContainerPort{{ContainerPort: port}},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path:   path,
+					Port:   intstr.FromInt32(port),
+					Scheme: corev1.URISchemeHTTP,
+				},
+			},
+			PeriodSeconds:  10,
+			TimeoutSeconds: 1,
+		},
+	}
+}
+
+func TestExtractHealthChecksFromPods_ProbePath(t *testing.T) {
+	service := newTestService("api", "default", map[string]string{"app": "api"},
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantURL string
+	}{
+		{
+			name:    "leading slash",
+			path:    "/healthz",
+			wantURL: "http://api.default.svc.cluster.local:80/healthz",
+		},
+		{
+			name:    "missing leading slash",
+			path:    "healthz",
+			wantURL: "http://api.default.svc.cluster.local:80/healthz",
+		},
+		{
+			name:    "empty path",
+			path:    "",
+			wantURL: "http://api.default.svc.cluster.local:80/",
+		},
+		{
+			name:    "query parameters",
+			path:    "/health?verbose=true&check=db",
+			wantURL: "http://api.default.svc.cluster.local:80/health?verbose=true&check=db",
+		},
+		{
+			name:    "query parameters without leading slash",
+			path:    "health?verbose=1",
+			wantURL: "http://api.default.svc.cluster.local:80/health?verbose=1",
+		},
+		{
+			name:    "escaped path",
+			path:    "/health%2Fdeep",
+			wantURL: "http://api.default.svc.cluster.local:80/health%2Fdeep",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("api-0", "default", map[string]string{"app": "api"},
+				newHTTPProbeContainer("app", 8080, tt.path))
+
+			got, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, DiscoveryOptions{})
+			want := []healthcheck.CheckConfig{
+				{
+					Name:     "default/api",
+					URL:      tt.wantURL,
+					Interval: 10 * time.Second,
+					Timeout:  time.Second,
+					Protocol: "http",
+				},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("extractHealthChecksFromPods() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func newTCPProbeContainer(name string, port int32) corev1.Container {
+	return corev1.Container{
+		Name:  name,
+		Ports: []corev1.ContainerPort{{Name: "postgres", ContainerPort: port}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("postgres")},
+			},
+			PeriodSeconds:  5,
+			TimeoutSeconds: 2,
+		},
+	}
+}
+
+func newGRPCProbeContainer(name string, port int32, service *string) corev1.Container {
+	return corev1.Container{
+		Name:  name,
+		Ports: []corev1.ContainerPort{{Name: "grpc", ContainerPort: port}},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				GRPC: &corev1.GRPCAction{Port: port, Service: service},
+			},
+			PeriodSeconds:  10,
+			TimeoutSeconds: 1,
+		},
+	}
+}
+
+func TestExtractHealthChecksFromPods_GRPCProbe(t *testing.T) {
+	selector := map[string]string{"app": "users"}
+	service := newTestService("users", "default", selector,
+		corev1.ServicePort{Port: 443, TargetPort: intstr.FromInt32(9090)})
+	named := "api.v1.Users"
+
+	tests := []struct {
+		name      string
+		container corev1.Container
+		want      []healthcheck.CheckConfig
+	}{
+		{
+			name:      "grpc liveness probe",
+			container: newGRPCProbeContainer("app", 9090, nil),
+			want: []healthcheck.CheckConfig{
+				{
+					Name:     "default/users",
+					URL:      "grpc://users.default.svc.cluster.local:443",
+					Interval: 10 * time.Second,
+					Timeout:  time.Second,
+					Protocol: "grpc",
+				},
+			},
+		},
+		{
+			name:      "grpc probe for a named service",
+			container: newGRPCProbeContainer("app", 9090, &named),
+			want: []healthcheck.CheckConfig{
+				{
+					Name:     "default/users",
+					URL:      "grpc://users.default.svc.cluster.local:443/api.v1.Users",
+					Interval: 10 * time.Second,
+					Timeout:  time.Second,
+					Protocol: "grpc",
+				},
+			},
+		},
+		{
+			name:      "grpc port not exposed by the service",
+			container: newGRPCProbeContainer("app", 9091, nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("users-0", "default", selector, tt.container)
+
+			got, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, DiscoveryOptions{})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractHealthChecksFromPods() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_TCPProbe(t *testing.T) {
+	selector := map[string]string{"app": "db"}
+	service := newTestService("db", "default", selector,
+		corev1.ServicePort{Port: 5432, TargetPort: intstr.FromInt32(5432)},
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+
+	httpLiveness := newHTTPProbeContainer("app", 8080, "/healthz")
+	httpLiveness.ReadinessProbe = newTCPProbeContainer("app", 5432).ReadinessProbe
+
+	tests := []struct {
+		name      string
+		container corev1.Container
+		want      []healthcheck.CheckConfig
+	}{
+		{
+			name:      "tcp readiness probe",
+			container: newTCPProbeContainer("postgres", 5432),
+			want: []healthcheck.CheckConfig{
+				{
+					Name:     "default/db",
+					URL:      "tcp://db.default.svc.cluster.local:5432",
+					Interval: 5 * time.Second,
+					Timeout:  2 * time.Second,
+					Protocol: "tcp",
+				},
+			},
+		},
+		{
+			name:      "http liveness probe takes precedence",
+			container: httpLiveness,
+			want: []healthcheck.CheckConfig{
+				{
+					Name:     "default/db",
+					URL:      "http://db.default.svc.cluster.local:80/healthz",
+					Interval: 10 * time.Second,
+					Timeout:  time.Second,
+					Protocol: "http",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("db-0", "default", selector, tt.container)
+
+			got, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, DiscoveryOptions{})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractHealthChecksFromPods() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_Deduplication(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)},
+		corev1.ServicePort{Name: "admin", Port: 9090, TargetPort: intstr.FromInt32(9090)})
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		wantURLs []string
+	}{
+		{
+			name: "replicas of the same container",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz")),
+				newTestPod("api-1", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz")),
+			},
+			wantURLs: []string{"http://api.default.svc.cluster.local:80/healthz"},
+		},
+		{
+			name: "two containers with different probe paths",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector,
+					newHTTPProbeContainer("app", 8080, "/healthz"),
+					newHTTPProbeContainer("admin", 9090, "/admin/health")),
+			},
+			wantURLs: []string{
+				"http://api.default.svc.cluster.local:80/healthz",
+				"http://api.default.svc.cluster.local:9090/admin/health",
+			},
+		},
+		{
+			name: "two containers with different probe paths across replicas",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector,
+					newHTTPProbeContainer("app", 8080, "/healthz"),
+					newHTTPProbeContainer("admin", 9090, "/admin/health")),
+				newTestPod("api-1", "default", selector,
+					newHTTPProbeContainer("app", 8080, "/healthz"),
+					newHTTPProbeContainer("admin", 9090, "/admin/health")),
+			},
+			wantURLs: []string{
+				"http://api.default.svc.cluster.local:80/healthz",
+				"http://api.default.svc.cluster.local:9090/admin/health",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, _ := extractHealthChecksFromPods(service, tt.pods, DiscoveryOptions{})
+
+			var gotURLs []string
+			for _, check := range checks {
+				gotURLs = append(gotURLs, check.URL)
+			}
+			if !reflect.DeepEqual(gotURLs, tt.wantURLs) {
+				t.Errorf("extractHealthChecksFromPods() urls = %v, want %v", gotURLs, tt.wantURLs)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_UnmappedProbePort(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)})
+
+	tests := []struct {
+		name         string
+		pods         []corev1.Pod
+		wantChecks   int
+		wantWarnings []string
+	}{
+		{
+			name: "mapped probe port has no warning",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz")),
+			},
+			wantChecks: 1,
+		},
+		{
+			name: "probe port absent from service",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 9090, "/healthz")),
+			},
+			wantWarnings: []string{
+				`liveness probe for container "app" targets port 9090, which is not exposed by the service`,
+			},
+		},
+		{
+			name: "replicas report the unmapped port once",
+			pods: []corev1.Pod{
+				newTestPod("api-0", "default", selector,
+					newHTTPProbeContainer("app", 8080, "/healthz"),
+					newHTTPProbeContainer("sidecar", 9090, "/healthz")),
+				newTestPod("api-1", "default", selector,
+					newHTTPProbeContainer("app", 8080, "/healthz"),
+					newHTTPProbeContainer("sidecar", 9090, "/healthz")),
+			},
+			wantChecks: 1,
+			wantWarnings: []string{
+				`liveness probe for container "sidecar" targets port 9090, which is not exposed by the service`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, warnings := extractHealthChecksFromPods(service, tt.pods, DiscoveryOptions{})
+			if len(checks) != tt.wantChecks {
+				t.Errorf("extractHealthChecksFromPods() checks = %v, want %v", len(checks), tt.wantChecks)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("extractHealthChecksFromPods() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_UnknownPhase(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	unknown := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+	unknown.Status.Phase = corev1.PodUnknown
+	wantWarning := `pod "api-0" is in phase Unknown, its node may be unreachable`
+
+	tests := []struct {
+		name         string
+		options      DiscoveryOptions
+		wantChecks   int
+		wantWarnings []string
+	}{
+		{
+			name:         "excluded by default",
+			wantChecks:   0,
+			wantWarnings: []string{wantWarning},
+		},
+		{
+			name:         "included when enabled",
+			options:      DiscoveryOptions{IncludeUnknownPods: true},
+			wantChecks:   1,
+			wantWarnings: []string{wantWarning},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, warnings := extractHealthChecksFromPods(service, []corev1.Pod{unknown}, tt.options)
+			if len(checks) != tt.wantChecks {
+				t.Errorf("extractHealthChecksFromPods() checks = %v, want %v", len(checks), tt.wantChecks)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("extractHealthChecksFromPods() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestShouldIncludePod(t *testing.T) {
+	deleted := metav1.Now()
+
+	tests := []struct {
+		name     string
+		phase    corev1.PodPhase
+		deleting bool
+		options  DiscoveryOptions
+		want     bool
+	}{
+		{name: "running", phase: corev1.PodRunning, want: true},
+		{name: "pending", phase: corev1.PodPending, want: true},
+		{name: "succeeded", phase: corev1.PodSucceeded, want: false},
+		{name: "failed", phase: corev1.PodFailed, want: false},
+		{name: "unknown excluded", phase: corev1.PodUnknown, want: false},
+		{
+			name:    "unknown included",
+			phase:   corev1.PodUnknown,
+			options: DiscoveryOptions{IncludeUnknownPods: true},
+			want:    true,
+		},
+		{name: "terminating", phase: corev1.PodRunning, deleting: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{Status: corev1.PodStatus{Phase: tt.phase}}
+			if tt.deleting {
+				pod.DeletionTimestamp = &deleted
+			}
+			if got := shouldIncludePod(pod, tt.options, time.Now()); got != tt.want {
+				t.Errorf("shouldIncludePod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldIncludePod_CompletedPodGrace(t *testing.T) {
+	now := time.Now()
+	grace := DiscoveryOptions{CompletedPodGrace: time.Minute}
+
+	finishedPod := func(phase corev1.PodPhase, age time.Duration) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					FinishedAt: metav1.NewTime(now.Add(-age)),
+				}},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		pod     corev1.Pod
+		options DiscoveryOptions
+		want    bool
+	}{
+		{name: "just succeeded without grace", pod: finishedPod(corev1.PodSucceeded, time.Second), want: false},
+		{
+			name:    "just succeeded",
+			pod:     finishedPod(corev1.PodSucceeded, time.Second),
+			options: grace,
+			want:    true,
+		},
+		{
+			name:    "failed within grace",
+			pod:     finishedPod(corev1.PodFailed, 59*time.Second),
+			options: grace,
+			want:    true,
+		},
+		{
+			name:    "succeeded past grace",
+			pod:     finishedPod(corev1.PodSucceeded, 2*time.Minute),
+			options: grace,
+			want:    false,
+		},
+		{
+			name: "ready condition fallback within grace",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Second)),
+				}},
+			}},
+			options: grace,
+			want:    true,
+		},
+		{
+			name:    "no completion time",
+			pod:     corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+			options: grace,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldIncludePod(tt.pod, tt.options, now); got != tt.want {
+				t.Errorf("shouldIncludePod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletedPodGraceRemaining(t *testing.T) {
+	now := time.Now()
+	grace := DiscoveryOptions{CompletedPodGrace: time.Minute}
+
+	finishedPod := func(age time.Duration) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					FinishedAt: metav1.NewTime(now.Add(-age)),
+				}},
+			}},
+		}}
+	}
+	running := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+
+	tests := []struct {
+		name    string
+		pods    []corev1.Pod
+		options DiscoveryOptions
+		want    time.Duration
+	}{
+		{name: "only running pods", pods: []corev1.Pod{running}, options: grace, want: 0},
+		{name: "completed pod without grace", pods: []corev1.Pod{finishedPod(10 * time.Second)}, want: 0},
+		{name: "completed pod past grace", pods: []corev1.Pod{finishedPod(2 * time.Minute)}, options: grace, want: 0},
+		{
+			name:    "completed pod within grace",
+			pods:    []corev1.Pod{running, finishedPod(20 * time.Second)},
+			options: grace,
+			want:    41 * time.Second,
+		},
+		{
+			name:    "earliest of several completed pods",
+			pods:    []corev1.Pod{finishedPod(10 * time.Second), finishedPod(50 * time.Second)},
+			options: grace,
+			want:    11 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completedPodGraceRemaining(tt.pods, tt.options, now); got != tt.want {
+				t.Errorf("completedPodGraceRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_CompletedPod(t *testing.T) {
+	selector := map[string]string{"app": "job"}
+	service := newTestService("job", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	pod := newTestPod("job-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+	pod.Status.Phase = corev1.PodSucceeded
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			FinishedAt: metav1.NewTime(time.Now().Add(-5 * time.Second)),
+		}},
+	}}
+
+	tests := []struct {
+		name         string
+		options      DiscoveryOptions
+		wantChecks   int
+		wantWarnings []string
+	}{
+		{
+			name:       "excluded by default",
+			wantChecks: 0,
+		},
+		{
+			name:         "kept and tagged within grace",
+			options:      DiscoveryOptions{CompletedPodGrace: time.Minute},
+			wantChecks:   1,
+			wantWarnings: []string{`pod "job-0" completed with phase Succeeded`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, warnings := extractHealthChecksFromPods(service, []corev1.Pod{pod}, tt.options)
+			if len(checks) != tt.wantChecks {
+				t.Errorf("extractHealthChecksFromPods() checks = %v, want %v", len(checks), tt.wantChecks)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("extractHealthChecksFromPods() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_ClampsInterval(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	service := newTestService("api", "default", selector,
+		corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+	bounded := DiscoveryOptions{MinInterval: 5 * time.Second, MaxInterval: 5 * time.Minute}
+
+	tests := []struct {
+		name         string
+		period       int32
+		options      DiscoveryOptions
+		wantInterval time.Duration
+	}{
+		{name: "too fast clamped up", period: 1, options: bounded, wantInterval: 5 * time.Second},
+		{name: "too slow clamped down", period: 600, options: bounded, wantInterval: 5 * time.Minute},
+		{name: "within bounds", period: 30, options: bounded, wantInterval: 30 * time.Second},
+		{name: "unbounded", period: 1, wantInterval: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := newHTTPProbeContainer("app", 8080, "/healthz")
+			container.LivenessProbe.PeriodSeconds = tt.period
+			pod := newTestPod("api-0", "default", selector, container)
+
+			checks, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, tt.options)
+			if len(checks) != 1 {
+				t.Fatalf("extractHealthChecksFromPods() checks = %v, want 1", len(checks))
+			}
+			if checks[0].Interval != tt.wantInterval {
+				t.Errorf("extractHealthChecksFromPods() interval = %v, want %v", checks[0].Interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_ClusterDomain(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+
+	tests := []struct {
+		name        string
+		opts        []DiscoveryOpt
+		annotations map[string]string
+		wantURL     string
+	}{
+		{
+			name:    "default domain",
+			wantURL: "http://api.default.svc.cluster.local:80/healthz",
+		},
+		{
+			name:    "custom domain",
+			opts:    []DiscoveryOpt{WithClusterDomain("k8s.example.com.")},
+			wantURL: "http://api.default.svc.k8s.example.com:80/healthz",
+		},
+		{
+			name:        "host override",
+			opts:        []DiscoveryOpt{WithClusterDomain("k8s.example.com")},
+			annotations: map[string]string{checkHostAnnotation: "api.staging.example.com"},
+			wantURL:     "http://api.staging.example.com:80/healthz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("api", "default", selector,
+				corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)})
+			service.Annotations = tt.annotations
+			pod := newTestPod("api-0", "default", selector, newHTTPProbeContainer("app", 8080, "/healthz"))
+
+			checks, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, newDiscoveryOptions(tt.opts...))
+			if len(checks) != 1 {
+				t.Fatalf("extractHealthChecksFromPods() checks = %v, want 1", len(checks))
+			}
+			if checks[0].URL != tt.wantURL {
+				t.Errorf("extractHealthChecksFromPods() url = %v, want %v", checks[0].URL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_DefaultCheck(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	unprobed := corev1.Container{Name: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}
+
+	tests := []struct {
+		name        string
+		opts        []DiscoveryOpt
+		annotations map[string]string
+		container   corev1.Container
+		wantURLs    []string
+	}{
+		{
+			name:      "no probe and no annotation",
+			container: unprobed,
+		},
+		{
+			name:        "no probe with annotation",
+			annotations: map[string]string{defaultCheckPathAnnotation: "/status"},
+			container:   unprobed,
+			wantURLs:    []string{"http://api.default.svc.cluster.local:80/status"},
+		},
+		{
+			name:        "probe takes precedence over annotation",
+			annotations: map[string]string{defaultCheckPathAnnotation: "/status"},
+			container:   newHTTPProbeContainer("app", 8080, "/healthz"),
+			wantURLs:    []string{"http://api.default.svc.cluster.local:80/healthz"},
+		},
+		{
+			name:      "no probe with default path option",
+			opts:      []DiscoveryOpt{WithDefaultCheckPath("healthz")},
+			container: unprobed,
+			wantURLs:  []string{"http://api.default.svc.cluster.local:80/healthz"},
+		},
+		{
+			name:        "annotation overrides default path option",
+			opts:        []DiscoveryOpt{WithDefaultCheckPath("/healthz")},
+			annotations: map[string]string{defaultCheckPathAnnotation: "/status"},
+			container:   unprobed,
+			wantURLs:    []string{"http://api.default.svc.cluster.local:80/status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("api", "default", selector,
+				corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)},
+				corev1.ServicePort{Port: 9090, TargetPort: intstr.FromInt32(9090)})
+			service.Annotations = tt.annotations
+			pod := newTestPod("api-0", "default", selector, tt.container)
+
+			checks, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, newDiscoveryOptions(tt.opts...))
+			var urls []string
+			for _, check := range checks {
+				urls = append(urls, check.URL)
+			}
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("extractHealthChecksFromPods() urls = %v, want %v", urls, tt.wantURLs)
+			}
+			if len(checks) == 1 && (checks[0].Name != "default/api" || checks[0].Interval != defaultCheckInterval) {
+				t.Errorf("extractHealthChecksFromPods() check = %+v, want default/api every %v", checks[0], defaultCheckInterval)
+			}
+		})
+	}
+}
+
+func TestExtractHealthChecksFromPods_DefaultCheckSkipsNonTCPPorts(t *testing.T) {
+	selector := map[string]string{"k8s-app": "kube-dns"}
+	unprobed := corev1.Container{Name: "coredns"}
+	options := newDiscoveryOptions(WithDefaultCheckPath("/healthz"))
+
+	tests := []struct {
+		name     string
+		ports    []corev1.ServicePort
+		wantURLs []string
+	}{
+		{
+			name:  "only udp",
+			ports: []corev1.ServicePort{{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP}},
+		},
+		{
+			name: "udp before tcp",
+			ports: []corev1.ServicePort{
+				{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP},
+				{Name: "metrics", Port: 9153, Protocol: corev1.ProtocolTCP},
+			},
+			wantURLs: []string{"http://kube-dns.kube-system.svc.cluster.local:9153/healthz"},
+		},
+		{
+			name:     "protocol defaults to tcp",
+			ports:    []corev1.ServicePort{{Name: "metrics", Port: 9153}},
+			wantURLs: []string{"http://kube-dns.kube-system.svc.cluster.local:9153/healthz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("kube-dns", "kube-system", selector, tt.ports...)
+			pod := newTestPod("coredns-0", "kube-system", selector, unprobed)
+
+			checks, _ := extractHealthChecksFromPods(service, []corev1.Pod{pod}, options)
+			var urls []string
+			for _, check := range checks {
+				urls = append(urls, check.URL)
+			}
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("extractHealthChecksFromPods() urls = %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
+
+func TestTargetMetadata_MaxConcurrentChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{name: "positive limit", value: "3", want: 3},
+		{name: "surrounding whitespace", value: " 2 ", want: 2},
+		{name: "zero is unlimited", value: "0", want: 0},
+		{name: "negative is unlimited", value: "-1", want: 0},
+		{name: "not a number", value: "many", want: 0},
+		{name: "unset", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := corev1.Service{}
+			if tt.value != "" {
+				service.Annotations = map[string]string{maxConcurrentChecksAnnotation: tt.value}
+			}
+			if got := targetMetadata(service, nil).MaxConcurrentChecks; got != tt.want {
+				t.Errorf("targetMetadata() MaxConcurrentChecks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package healthcheck
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// Aggregation decides the status of a service from the statuses of its checks
+type Aggregation string
+
+const (
+	// AggregateWorstOf reports a service unhealthy as soon as any check fails
+	AggregateWorstOf Aggregation = "worst-of"
+	// AggregateAllOf reports a service unhealthy only once every check fails
+	AggregateAllOf Aggregation = "all-of"
+)
+
+// ParseAggregation returns the Aggregation named by s
+func ParseAggregation(s string) (Aggregation, error) {
+	aggregation := Aggregation(s)
+	if aggregation != AggregateWorstOf && aggregation != AggregateAllOf {
+		return "", fmt.Errorf("unsupported check aggregation %q, must be %s or %s", s, AggregateWorstOf, AggregateAllOf)
+	}
+	return aggregation, nil
+}
+
+// WithAggregation sets how the statuses of a service's checks combine into
+// its status. Unrecognized values keep the default of worst-of.
+func WithAggregation(aggregation Aggregation) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		if aggregation != AggregateWorstOf && aggregation != AggregateAllOf {
+			return
+		}
+		hc.aggregation = aggregation
+	}
+}
+
+// aggregate combines check statuses. Unknown only wins when no check has a
+// conclusive result for the policy.
+func (a Aggregation) aggregate(checks []types.CheckHealth) types.HealthStatus {
+	counts := make(map[types.HealthStatus]int)
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+
+	if a == AggregateAllOf {
+		if len(checks) > 0 && counts[types.HealthStatusUnhealthy] == len(checks) {
+			return types.HealthStatusUnhealthy
+		}
+		if counts[types.HealthStatusHealthy] > 0 {
+			return types.HealthStatusHealthy
+		}
+		return types.HealthStatusUnknown
+	}
+
+	if counts[types.HealthStatusUnhealthy] > 0 {
+		return types.HealthStatusUnhealthy
+	}
+	if len(checks) > 0 && counts[types.HealthStatusHealthy] == len(checks) {
+		return types.HealthStatusHealthy
+	}
+	return types.HealthStatusUnknown
+}
+
+// checkName identifies a check within its target. Discovered checks are named
+// after the target, so they are told apart by URL instead.
+func checkName(cfg CheckConfig) string {
+	if cfg.Name == "" || cfg.Name == cfg.targetName() {
+		return cfg.URL
+	}
+	return cfg.Name
+}
+
+// targetName is the target whose health a check's results are recorded under
+func (cfg CheckConfig) targetName() string {
+	if cfg.target != "" {
+		return cfg.target
+	}
+	return cfg.Name
+}
+
+// withCheck returns checks with the entry of the same name replaced by check,
+// sorted by name. The input is shared with readers and is not modified.
+func withCheck(checks []types.CheckHealth, check types.CheckHealth) []types.CheckHealth {
+	updated := make([]types.CheckHealth, 0, len(checks)+1)
+	for _, existing := range checks {
+		if existing.Name != check.Name {
+			updated = append(updated, existing)
+		}
+	}
+	updated = append(updated, check)
+	slices.SortFunc(updated, func(a, b types.CheckHealth) int { return strings.Compare(a.Name, b.Name) })
+	return updated
+}
+
+// pruneChecks drops the results of checks a target no longer has after it is
+// registered again, so they stop counting towards its status
+func (hc *HealthChecker) pruneChecks(target HealthTarget) {
+	names := make(map[string]bool, len(target.Checks))
+	for _, check := range target.Checks {
+		check.target = target.Name
+		names[checkName(check)] = true
+	}
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	namespace, service := parseTargetName(target.Name)
+	key := namespace + "/" + service
+	existing, exists := hc.healthData.Get(key)
+	if !exists {
+		return
+	}
+
+	checks := slices.DeleteFunc(slices.Clone(existing.Checks), func(check types.CheckHealth) bool {
+		return !names[check.Name]
+	})
+	if len(checks) == len(existing.Checks) {
+		return
+	}
+
+	info := *existing
+	info.Checks = checks
+	info.Status = hc.serviceStatus(key, checks)
+	hc.healthData.Set(key, &info)
+}
+
+// serviceStatus aggregates the checks of a target, reporting failures as
+// maintenance while the target is under maintenance
+func (hc *HealthChecker) serviceStatus(key string, checks []types.CheckHealth) types.HealthStatus {
+	status := hc.aggregation.aggregate(checks)
+	if status == types.HealthStatusUnhealthy && hc.underMaintenance(key) {
+		return types.HealthStatusMaintenance
+	}
+	return status
+}
@@ -0,0 +1,180 @@
+package healthcheck
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestAggregation_Aggregate(t *testing.T) {
+	healthy := types.CheckHealth{Status: types.HealthStatusHealthy}
+	unhealthy := types.CheckHealth{Status: types.HealthStatusUnhealthy}
+	unknown := types.CheckHealth{Status: types.HealthStatusUnknown}
+
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		checks      []types.CheckHealth
+		want        types.HealthStatus
+	}{
+		{name: "worst-of all healthy", aggregation: AggregateWorstOf, checks: []types.CheckHealth{healthy, healthy}, want: types.HealthStatusHealthy},
+		{name: "worst-of one failing", aggregation: AggregateWorstOf, checks: []types.CheckHealth{healthy, unhealthy}, want: types.HealthStatusUnhealthy},
+		{name: "worst-of unknown", aggregation: AggregateWorstOf, checks: []types.CheckHealth{healthy, unknown}, want: types.HealthStatusUnknown},
+		{name: "all-of one failing", aggregation: AggregateAllOf, checks: []types.CheckHealth{healthy, unhealthy}, want: types.HealthStatusHealthy},
+		{name: "all-of all failing", aggregation: AggregateAllOf, checks: []types.CheckHealth{unhealthy, unhealthy}, want: types.HealthStatusUnhealthy},
+		{name: "all-of failing and unknown", aggregation: AggregateAllOf, checks: []types.CheckHealth{unhealthy, unknown}, want: types.HealthStatusUnknown},
+		{name: "no checks", aggregation: AggregateWorstOf, want: types.HealthStatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.aggregation.aggregate(tt.checks); got != tt.want {
+				t.Errorf("aggregate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAggregation(t *testing.T) {
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		want        Aggregation
+	}{
+		{name: "worst-of", aggregation: AggregateWorstOf, want: AggregateWorstOf},
+		{name: "all-of", aggregation: AggregateAllOf, want: AggregateAllOf},
+		{name: "unrecognized keeps default", aggregation: "any-of", want: AggregateWorstOf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithAggregation(tt.aggregation))
+			if hc.aggregation != tt.want {
+				t.Errorf("WithAggregation() = %v, want %v", hc.aggregation, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAggregation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Aggregation
+		wantErr bool
+	}{
+		{name: "worst-of", value: "worst-of", want: AggregateWorstOf},
+		{name: "all-of", value: "all-of", want: AggregateAllOf},
+		{name: "unrecognized", value: "any-of", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAggregation(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAggregation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAggregation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_AggregatesChecks(t *testing.T) {
+	liveness := CheckConfig{Name: "liveness", URL: "http://api:8080/livez", target: "default/api"}
+	readiness := CheckConfig{Name: "readiness", URL: "http://api:8080/readyz", target: "default/api"}
+
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		want        types.HealthStatus
+	}{
+		{name: "worst-of", aggregation: AggregateWorstOf, want: types.HealthStatusUnhealthy},
+		{name: "all-of", aggregation: AggregateAllOf, want: types.HealthStatusHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithAggregation(tt.aggregation))
+			start := time.Now()
+
+			// Record in both orders so the result does not depend on which check finished last
+			hc.recordCheckResult(liveness, start, 200, nil)
+			hc.recordCheckResult(readiness, start.Add(time.Second), 503, nil)
+			hc.recordCheckResult(liveness, start.Add(2*time.Second), 200, nil)
+
+			info, exists := hc.GetHealthData("default/api")
+			if !exists {
+				t.Fatalf("GetHealthData() exists = false, want true")
+			}
+			if info.Status != tt.want {
+				t.Errorf("recordCheckResult() status = %v, want %v", info.Status, tt.want)
+			}
+
+			got := make([]string, 0, len(info.Checks))
+			for _, check := range info.Checks {
+				got = append(got, check.Name+"="+string(check.Status))
+			}
+			want := []string{"liveness=healthy", "readiness=unhealthy"}
+			if !slices.Equal(got, want) {
+				t.Errorf("recordCheckResult() checks = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_DiscoveredChecksNamedByURL(t *testing.T) {
+	hc := NewHealthChecker()
+	start := time.Now()
+	hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api:8080/livez"}, start, 200, nil)
+	hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api:9090/readyz"}, start, 500, nil)
+
+	info, _ := hc.GetHealthData("default/api")
+	if len(info.Checks) != 2 {
+		t.Fatalf("recordCheckResult() checks = %v, want 2", info.Checks)
+	}
+	if info.Checks[0].Name != "http://api:8080/livez" || info.Checks[1].Name != "http://api:9090/readyz" {
+		t.Errorf("recordCheckResult() check names = %v, %v, want the check URLs", info.Checks[0].Name, info.Checks[1].Name)
+	}
+	if info.Status != types.HealthStatusUnhealthy {
+		t.Errorf("recordCheckResult() status = %v, want %v", info.Status, types.HealthStatusUnhealthy)
+	}
+}
+
+func TestHealthChecker_PruneChecks(t *testing.T) {
+	liveness := CheckConfig{Name: "liveness", URL: "http://api:8080/livez", target: "default/api"}
+	readiness := CheckConfig{Name: "readiness", URL: "http://api:8080/readyz", target: "default/api"}
+
+	hc := NewHealthChecker()
+	start := time.Now()
+	hc.recordCheckResult(liveness, start, 200, nil)
+	hc.recordCheckResult(readiness, start, 503, nil)
+
+	hc.pruneChecks(HealthTarget{Name: "default/api", Checks: []CheckConfig{liveness}})
+
+	info, _ := hc.GetHealthData("default/api")
+	if len(info.Checks) != 1 || info.Checks[0].Name != "liveness" {
+		t.Errorf("pruneChecks() checks = %v, want only liveness", info.Checks)
+	}
+	if info.Status != types.HealthStatusHealthy {
+		t.Errorf("pruneChecks() status = %v, want %v", info.Status, types.HealthStatusHealthy)
+	}
+}
+
+func TestHealthChecker_PruneAllChecks(t *testing.T) {
+	liveness := CheckConfig{Name: "liveness", URL: "http://api:8080/livez", target: "default/api"}
+	readiness := CheckConfig{Name: "readiness", URL: "http://api:8080/readyz", target: "default/api"}
+
+	hc := NewHealthChecker()
+	hc.recordCheckResult(liveness, time.Now(), 503, nil)
+
+	hc.pruneChecks(HealthTarget{Name: "default/api", Checks: []CheckConfig{readiness}})
+
+	info, _ := hc.GetHealthData("default/api")
+	if len(info.Checks) != 0 {
+		t.Errorf("pruneChecks() checks = %v, want none", info.Checks)
+	}
+	if info.Status != types.HealthStatusUnknown {
+		t.Errorf("pruneChecks() status = %v, want %v", info.Status, types.HealthStatusUnknown)
+	}
+}
@@ -0,0 +1,92 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+const alertTimeout = 10 * time.Second
+
+// AlertConfig configures the webhook notified when a service stays unhealthy
+type AlertConfig struct {
+	WebhookURL string
+	// Threshold is the number of consecutive unhealthy checks that fire an alert
+	Threshold int
+}
+
+// Alert is the JSON payload posted to the alert webhook
+type Alert struct {
+	Namespace           string             `json:"namespace"`
+	ServiceName         string             `json:"service_name"`
+	Status              types.HealthStatus `json:"status"`
+	ConsecutiveFailures int                `json:"consecutive_failures"`
+	URL                 string             `json:"url"`
+	Error               string             `json:"error,omitempty"`
+	Timestamp           time.Time          `json:"timestamp"`
+}
+
+// WithAlertConfig enables alerting for services that fail cfg.Threshold consecutive checks
+func WithAlertConfig(cfg AlertConfig) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.alertConfig = cfg
+	}
+}
+
+func (hc *HealthChecker) alertingEnabled() bool {
+	return hc.alertConfig.WebhookURL != "" && hc.alertConfig.Threshold > 0
+}
+
+// evaluateAlert fires at most one alert per unhealthy episode. Callers must hold hc.mu.
+func (hc *HealthChecker) evaluateAlert(key string, info types.ServiceHealthInfo) {
+	if !hc.alertingEnabled() {
+		return
+	}
+
+	if info.Status == types.HealthStatusHealthy {
+		delete(hc.alertsFired, key)
+		return
+	}
+
+	failures := consecutiveUnhealthy(info.History)
+	if failures < hc.alertConfig.Threshold || hc.alertsFired[key] {
+		return
+	}
+	hc.alertsFired[key] = true
+
+	last := info.History[len(info.History)-1]
+	alert := Alert{
+		Namespace:           info.Namespace,
+		ServiceName:         info.ServiceName,
+		Status:              info.Status,
+		ConsecutiveFailures: failures,
+		URL:                 info.URL,
+		Error:               last.Error,
+		Timestamp:           last.Timestamp,
+	}
+	go hc.sendAlert(alert)
+}
+
+func consecutiveUnhealthy(history []types.HealthCheckEntry) int {
+	count := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Status != types.HealthStatusUnhealthy {
+			return count
+		}
+		count++
+	}
+	return count
+}
+
+func (hc *HealthChecker) sendAlert(alert Alert) {
+	ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancel()
+
+	if err := postJSON(ctx, hc.alertClient, hc.alertConfig.WebhookURL, alert); err != nil {
+		log.Log.WithName("healthcheck").Error(err, "failed to send alert",
+			"service", alert.Namespace+"/"+alert.ServiceName)
+	}
+}
@@ -0,0 +1,101 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_AlertWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		results    []int
+		wantAlerts int
+	}{
+		{
+			name:       "below threshold does not alert",
+			threshold:  3,
+			results:    []int{500, 500, 200, 500, 500},
+			wantAlerts: 0,
+		},
+		{
+			name:       "fires once per unhealthy episode",
+			threshold:  3,
+			results:    []int{500, 500, 500, 500, 500, 500},
+			wantAlerts: 1,
+		},
+		{
+			name:       "recovery resets the episode",
+			threshold:  2,
+			results:    []int{500, 500, 500, 200, 500, 500},
+			wantAlerts: 2,
+		},
+		{
+			name:       "request errors count as failures",
+			threshold:  2,
+			results:    []int{0, 0, 0},
+			wantAlerts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var alerts []Alert
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var alert Alert
+				if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+					t.Errorf("webhook received invalid payload: %v", err)
+				}
+				mu.Lock()
+				alerts = append(alerts, alert)
+				mu.Unlock()
+			}))
+			defer webhook.Close()
+
+			hc := NewHealthChecker(WithAlertConfig(AlertConfig{
+				WebhookURL: webhook.URL,
+				Threshold:  tt.threshold,
+			}))
+			cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+			for _, code := range tt.results {
+				var err error
+				if code == 0 {
+					err = errors.New("timeout")
+				}
+				hc.recordCheckResult(cfg, time.Now(), code, err)
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) {
+				mu.Lock()
+				got := len(alerts)
+				mu.Unlock()
+				if got >= tt.wantAlerts {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			// Give any unexpected extra alerts a chance to arrive
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(alerts) != tt.wantAlerts {
+				t.Fatalf("webhook received %v alerts, want %v", len(alerts), tt.wantAlerts)
+			}
+			for _, alert := range alerts {
+				if alert.Namespace != "default" || alert.ServiceName != "api" {
+					t.Errorf("alert service = %s/%s, want default/api", alert.Namespace, alert.ServiceName)
+				}
+				if alert.ConsecutiveFailures != tt.threshold {
+					t.Errorf("alert consecutive failures = %v, want %v", alert.ConsecutiveFailures, tt.threshold)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxBodyBytes bounds how much of a response body is read for body assertions
+const maxBodyBytes = 64 << 10
+
+func (cfg CheckConfig) hasBodyExpectation() bool {
+	return cfg.ExpectBodyContains != "" || cfg.ExpectJSONPath != ""
+}
+
+// checkBody asserts that body satisfies the expectations of cfg
+func checkBody(cfg CheckConfig, body []byte) error {
+	if cfg.ExpectBodyContains != "" && !bytes.Contains(body, []byte(cfg.ExpectBodyContains)) {
+		return fmt.Errorf("response body does not contain %q", cfg.ExpectBodyContains)
+	}
+	if cfg.ExpectJSONPath == "" {
+		return nil
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("response body is not JSON: %w", err)
+	}
+	value, err := lookupJSONPath(document, cfg.ExpectJSONPath)
+	if err != nil {
+		return err
+	}
+	got := formatJSONValue(value)
+	if got != cfg.ExpectJSONValue {
+		return fmt.Errorf("%s is %q, want %q", cfg.ExpectJSONPath, got, cfg.ExpectJSONValue)
+	}
+	return nil
+}
+
+// lookupJSONPath follows a dot-separated path of object keys and array
+// indexes, e.g. "checks.0.status"
+func lookupJSONPath(document any, path string) (any, error) {
+	current := document
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, exists := node[segment]
+			if !exists {
+				return nil, fmt.Errorf("%s not found in response body", path)
+			}
+			current = value
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("%s not found in response body", path)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("%s not found in response body", path)
+		}
+	}
+	return current, nil
+}
+
+// formatJSONValue renders strings as-is and everything else as JSON, so
+// expectations like "ok", "true" and "1" all read naturally
+func formatJSONValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
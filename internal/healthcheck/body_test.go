@@ -0,0 +1,169 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_BodyExpectations(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		body         string
+		contains     string
+		jsonPath     string
+		jsonValue    string
+		wantStatus   types.HealthStatus
+		wantErrorHas string
+	}{
+		{
+			name:       "no expectations ignores body",
+			statusCode: http.StatusOK,
+			body:       `{"status":"degraded"}`,
+			wantStatus: "healthy",
+		},
+		{
+			name:       "body contains match",
+			statusCode: http.StatusOK,
+			body:       `all systems ok`,
+			contains:   "ok",
+			wantStatus: "healthy",
+		},
+		{
+			name:         "body contains mismatch",
+			statusCode:   http.StatusOK,
+			body:         `{"status":"degraded"}`,
+			contains:     `"status":"ok"`,
+			wantStatus:   "unhealthy",
+			wantErrorHas: "does not contain",
+		},
+		{
+			name:       "json value match",
+			statusCode: http.StatusOK,
+			body:       `{"status":"ok"}`,
+			jsonPath:   "status",
+			jsonValue:  "ok",
+			wantStatus: "healthy",
+		},
+		{
+			name:         "json value mismatch",
+			statusCode:   http.StatusOK,
+			body:         `{"status":"degraded"}`,
+			jsonPath:     "status",
+			jsonValue:    "ok",
+			wantStatus:   "unhealthy",
+			wantErrorHas: `status is "degraded", want "ok"`,
+		},
+		{
+			name:       "nested json path with array index",
+			statusCode: http.StatusOK,
+			body:       `{"checks":[{"name":"db","healthy":true}]}`,
+			jsonPath:   "checks.0.healthy",
+			jsonValue:  "true",
+			wantStatus: "healthy",
+		},
+		{
+			name:         "json path missing",
+			statusCode:   http.StatusOK,
+			body:         `{"state":"ok"}`,
+			jsonPath:     "status",
+			jsonValue:    "ok",
+			wantStatus:   "unhealthy",
+			wantErrorHas: "not found",
+		},
+		{
+			name:         "body is not json",
+			statusCode:   http.StatusOK,
+			body:         `ok`,
+			jsonPath:     "status",
+			jsonValue:    "ok",
+			wantStatus:   "unhealthy",
+			wantErrorHas: "not JSON",
+		},
+		{
+			name:       "non-2xx stays unhealthy without reading body",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"status":"ok"}`,
+			jsonPath:   "status",
+			jsonValue:  "ok",
+			wantStatus: "unhealthy",
+		},
+		{
+			name:         "match beyond the read limit is not seen",
+			statusCode:   http.StatusOK,
+			body:         strings.Repeat(" ", maxBodyBytes) + "ok",
+			contains:     "ok",
+			wantStatus:   "unhealthy",
+			wantErrorHas: "does not contain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:               "default/api",
+				URL:                server.URL,
+				Timeout:            time.Second,
+				Protocol:           "http",
+				ExpectBodyContains: tt.contains,
+				ExpectJSONPath:     tt.jsonPath,
+				ExpectJSONValue:    tt.jsonValue,
+			}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData(cfg.Name)
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, tt.wantStatus, info.History[0].Error)
+			}
+			if !strings.Contains(info.History[0].Error, tt.wantErrorHas) {
+				t.Errorf("executeCheck() error = %q, want it to contain %q", info.History[0].Error, tt.wantErrorHas)
+			}
+		})
+	}
+}
+
+func TestCoalesceKey(t *testing.T) {
+	base := CheckConfig{URL: "http://api/healthz", Protocol: "http", Timeout: time.Second}
+
+	tests := []struct {
+		name   string
+		modify func(*CheckConfig)
+		shared bool
+	}{
+		{name: "identical checks", modify: func(*CheckConfig) {}, shared: true},
+		{name: "different check name", modify: func(c *CheckConfig) { c.Name = "other" }, shared: true},
+		{name: "body expectation", modify: func(c *CheckConfig) { c.ExpectBodyContains = "ok" }},
+		{name: "json expectation", modify: func(c *CheckConfig) { c.ExpectJSONPath, c.ExpectJSONValue = "status", "ok" }},
+		{name: "authorization", modify: func(c *CheckConfig) { c.Authorization = "Bearer token" }},
+		{name: "post with body", modify: func(c *CheckConfig) { c.Method, c.RequestBody = http.MethodPost, `{}` }},
+		{name: "protocol", modify: func(c *CheckConfig) { c.Protocol = "https" }},
+		{name: "insecure skip verify", modify: func(c *CheckConfig) { c.InsecureSkipVerify = true }},
+		{name: "ca bundle", modify: func(c *CheckConfig) { c.CABundle = "-----BEGIN CERTIFICATE-----" }},
+		{name: "timeout", modify: func(c *CheckConfig) { c.Timeout = 30 * time.Second }},
+		{name: "disable keep-alives", modify: func(c *CheckConfig) { c.DisableKeepAlives = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.modify(&other)
+			if shared := coalesceKey(base) == coalesceKey(other); shared != tt.shared {
+				t.Errorf("coalesceKey() shared = %v, want %v", shared, tt.shared)
+			}
+		})
+	}
+}
@@ -2,18 +2,34 @@ package healthcheck
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kdwils/constellation/internal/cache"
 	"github.com/kdwils/constellation/internal/types"
 )
 
+// ErrTargetNotFound is returned when an operation refers to a target that is not registered
+var ErrTargetNotFound = errors.New("health target not found")
+
+// defaultHistorySize is how many check results are kept per service
+const defaultHistorySize = 100
+
+// maxDrainBytes bounds how much of a response body is read to allow connection reuse
+const maxDrainBytes = 64 << 10
+
 // HTTPClient interface for dependency injection during tests
 //
 //go:generate mockgen -destination=mocks/mock_http_client.go -package=mocks github.com/kdwils/constellation/internal/healthcheck HTTPClient
@@ -23,18 +39,47 @@ type HTTPClient interface {
 
 // HealthTarget represents a service or endpoint being monitored
 type HealthTarget struct {
-	Name   string
-	Checks []CheckConfig
+	Name   string        `json:"name"`
+	Checks []CheckConfig `json:"checks"`
 	cancel context.CancelFunc
 }
 
 // CheckConfig represents a single health check endpoint
 type CheckConfig struct {
-	Name     string
-	URL      string
-	Interval time.Duration
-	Timeout  time.Duration
-	Protocol string // "http", "tcp", "grpc"
+	Name     string        `json:"name"`
+	URL      string        `json:"url"`
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout"`
+	Protocol string        `json:"protocol"` // "http", "tcp", "grpc", "udp", "icmp", "replicas"
+	// DisableKeepAlives opens a fresh connection for every check instead of
+	// reusing one from the shared transport
+	DisableKeepAlives bool `json:"disable_keep_alives,omitempty"`
+	// InsecureSkipVerify accepts any certificate the server presents
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+	// CABundle is PEM-encoded certificates trusted instead of the system roots
+	CABundle string `json:"ca_bundle,omitempty"`
+	// ExpectBodyContains marks a 2xx response unhealthy unless its body contains this string
+	ExpectBodyContains string `json:"expect_body_contains,omitempty"`
+	// ExpectJSONPath and ExpectJSONValue mark a 2xx response unhealthy unless
+	// the dot-separated path into its JSON body holds the value
+	ExpectJSONPath  string `json:"expect_json_path,omitempty"`
+	ExpectJSONValue string `json:"expect_json_value,omitempty"`
+	// Payload is the datagram sent by udp checks, e.g. a DNS query
+	Payload string `json:"payload,omitempty"`
+	// Method is the HTTP method of http checks. Empty sends GET.
+	Method string `json:"method,omitempty"`
+	// RequestBody is sent by POST, PUT and PATCH http checks. For grpc checks
+	// it is the service name asked of the health service instead of the URL path.
+	RequestBody string `json:"request_body,omitempty"`
+	// ContentType is the Content-Type header sent with RequestBody
+	ContentType string `json:"content_type,omitempty"`
+	// Authorization is sent as the Authorization header. It is a credential, so
+	// it is never serialized.
+	Authorization string `json:"-"`
+
+	// target is set when the check is scheduled, since Name is the check's own
+	// name for checks that come from a HealthCheck
+	target string
 }
 
 // HealthChecker manages health checks for in-cluster services based on pod probes
@@ -42,12 +87,45 @@ type HealthChecker struct {
 	mu            sync.RWMutex
 	healthData    *cache.Cache[*types.ServiceHealthInfo]
 	healthTargets *cache.Cache[HealthTarget]
+	restored      *cache.Cache[*types.ServiceHealthInfo]
+	metadata      *cache.Cache[TargetMetadata]
+	maintenance   *cache.Cache[bool]
 	subscribers   map[chan []*types.ServiceHealthInfo]bool
 	subMu         sync.RWMutex
-	registerCh    chan HealthTarget
+	registerCh    chan string
 	unregisterCh  chan string
-	checkCh       chan CheckConfig
+	checkCh       chan queuedCheck
 	httpClient    HTTPClient
+	alertClient   HTTPClient
+	alertConfig   AlertConfig
+	alertsFired   map[string]bool
+	notifiers     []Notifier
+	exporters     []Exporter
+	aggregation   Aggregation
+	coalescer     *checkCoalescer
+	jitter        float64
+	historySize   int
+	workers       int
+	manualLimiter *manualCheckLimiter
+	targetLimiter *targetLimiter
+	eventLog      *eventLog
+	stats         *stateStats
+
+	workloadReader client.Reader
+
+	tlsMu      sync.Mutex
+	tlsClients map[tlsKey]*http.Client
+
+	enqueueTimeout       time.Duration
+	running              atomic.Bool
+	stopped              chan struct{}
+	stopOnce             sync.Once
+	pendingMu            sync.Mutex
+	pendingRegistrations map[string]HealthTarget
+
+	notifyDebounce time.Duration
+	notifyMu       sync.Mutex
+	notifyPending  bool
 }
 
 // NewHealthChecker creates a new health checker
@@ -55,11 +133,28 @@ func NewHealthChecker(opts ...HealthCheckerOpt) *HealthChecker {
 	hc := &HealthChecker{
 		healthData:    cache.New[*types.ServiceHealthInfo](),
 		healthTargets: cache.New[HealthTarget](),
+		restored:      cache.New[*types.ServiceHealthInfo](),
+		metadata:      cache.New[TargetMetadata](),
+		maintenance:   cache.New[bool](),
 		subscribers:   make(map[chan []*types.ServiceHealthInfo]bool),
-		registerCh:    make(chan HealthTarget, 100),
+		registerCh:    make(chan string, 100),
 		unregisterCh:  make(chan string, 100),
-		checkCh:       make(chan CheckConfig, 100),
-		httpClient:    http.DefaultClient,
+		checkCh:       make(chan queuedCheck, 100),
+		httpClient:    newDefaultHTTPClient(),
+		alertClient:   http.DefaultClient,
+		alertsFired:   make(map[string]bool),
+		tlsClients:    make(map[tlsKey]*http.Client),
+		historySize:   defaultHistorySize,
+		workers:       defaultWorkers,
+		aggregation:   AggregateWorstOf,
+		manualLimiter: newManualCheckLimiter(defaultManualCheckLimit),
+		targetLimiter: newTargetLimiter(),
+		eventLog:      newEventLog(defaultEventLogSize),
+		stats:         newStateStats(),
+
+		enqueueTimeout:       defaultEnqueueTimeout,
+		stopped:              make(chan struct{}),
+		pendingRegistrations: make(map[string]HealthTarget),
 	}
 
 	for _, opt := range opts {
@@ -69,8 +164,35 @@ func NewHealthChecker(opts ...HealthCheckerOpt) *HealthChecker {
 	return hc
 }
 
+// newDefaultHTTPClient returns a client whose transport keeps enough idle
+// connections per host for frequent checks against the same service to reuse them
+func newDefaultHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	return &http.Client{Transport: transport}
+}
+
 type HealthCheckerOpt func(*HealthChecker)
 
+// WithNotifyDebounce coalesces subscriber updates so subscribers receive at
+// most one per window. Zero notifies on every change.
+func WithNotifyDebounce(window time.Duration) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.notifyDebounce = window
+	}
+}
+
+// WithHistorySize sets how many check results are kept per service. Values
+// below one are ignored and the default of 100 is kept.
+func WithHistorySize(n int) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		if n < 1 {
+			return
+		}
+		hc.historySize = n
+	}
+}
+
 func WithHTTPClient(client HTTPClient) HealthCheckerOpt {
 	return func(hc *HealthChecker) {
 		hc.httpClient = client
@@ -81,41 +203,81 @@ func WithHTTPClient(client HTTPClient) HealthCheckerOpt {
 func (hc *HealthChecker) Start(ctx context.Context) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting health checker")
+	hc.running.Store(true)
+	defer hc.running.Store(false)
 
 	go hc.listenForRegistrations(ctx)
 	go hc.listenForUnregistrations(ctx)
 
+	// With a worker pool the workers consume checkCh, so the loop only waits
+	dispatch := hc.checkCh
+	if hc.workers > 0 {
+		dispatch = nil
+	}
+	workers := hc.startWorkers(ctx)
+
 	for {
 		select {
-		case cfg := <-hc.checkCh:
-			go hc.executeCheck(ctx, cfg)
+		case check := <-dispatch:
+			go hc.runQueued(ctx, check)
 		case <-ctx.Done():
+			hc.stopOnce.Do(func() { close(hc.stopped) })
+			workers.Wait()
 			return nil
 		}
 	}
 }
 
+// Running reports whether the Start loop is dispatching checks
+func (hc *HealthChecker) Running() bool {
+	return hc.running.Load()
+}
+
 func (hc *HealthChecker) listenForRegistrations(parentCtx context.Context) {
 	for {
 		select {
-		case target := <-hc.registerCh:
+		case name := <-hc.registerCh:
+			target, ok := hc.takeRegistration(name)
+			if !ok {
+				continue
+			}
 			existing, exists := hc.healthTargets.Get(target.Name)
 
-			if exists {
+			if exists && slices.Equal(existing.Checks, target.Checks) {
 				target.cancel = existing.cancel
 				hc.healthTargets.Set(target.Name, target)
 				hc.notifySubscribers()
 				continue
 			}
 
+			if exists && existing.cancel != nil {
+				existing.cancel()
+			}
+
+			if !exists {
+				hc.restoreHealthData(target.Name)
+			}
+			if exists {
+				hc.pruneChecks(target)
+			}
+
 			ctx, cancel := context.WithCancel(parentCtx)
 			target.cancel = cancel
 			hc.healthTargets.Set(target.Name, target)
 
 			for _, check := range target.Checks {
+				check.target = target.Name
 				go hc.runCheckTicker(ctx, check)
 			}
 
+			eventType := types.StateEventAdd
+			checksDelta := len(target.Checks)
+			if exists {
+				eventType = types.StateEventUpdate
+				checksDelta -= len(existing.Checks)
+			}
+			hc.stats.observe(target.Name, eventType, checksDelta)
+			hc.recordEvent(target.Name, eventType)
 			hc.notifySubscribers()
 
 		case <-parentCtx.Done():
@@ -128,6 +290,8 @@ func (hc *HealthChecker) listenForUnregistrations(ctx context.Context) {
 	for {
 		select {
 		case name := <-hc.unregisterCh:
+			hc.metadata.Delete(name)
+			hc.maintenance.Delete(name)
 			target, exists := hc.healthTargets.Get(name)
 			if !exists {
 				continue
@@ -138,7 +302,14 @@ func (hc *HealthChecker) listenForUnregistrations(ctx context.Context) {
 			}
 
 			hc.healthTargets.Delete(name)
+			hc.manualLimiter.forget(name)
+			hc.targetLimiter.forget(name)
+			hc.stats.observe(name, types.StateEventDelete, -len(target.Checks))
+			hc.recordEvent(name, types.StateEventDelete)
+			hc.mu.Lock()
 			hc.healthData.Delete(name)
+			delete(hc.alertsFired, name)
+			hc.mu.Unlock()
 			hc.notifySubscribers()
 
 		case <-ctx.Done():
@@ -148,21 +319,22 @@ func (hc *HealthChecker) listenForUnregistrations(ctx context.Context) {
 }
 
 func (hc *HealthChecker) runCheckTicker(ctx context.Context, cfg CheckConfig) {
+	if hc.jitter > 0 {
+		hc.runJitteredCheckTicker(ctx, cfg)
+		return
+	}
+
 	ticker := time.NewTicker(cfg.Interval)
 	defer ticker.Stop()
 
-	select {
-	case hc.checkCh <- cfg:
-	case <-ctx.Done():
+	if !hc.dispatch(ctx, cfg) {
 		return
 	}
 
 	for {
 		select {
 		case <-ticker.C:
-			select {
-			case hc.checkCh <- cfg:
-			case <-ctx.Done():
+			if !hc.dispatch(ctx, cfg) {
 				return
 			}
 		case <-ctx.Done():
@@ -173,67 +345,176 @@ func (hc *HealthChecker) runCheckTicker(ctx context.Context, cfg CheckConfig) {
 
 func (hc *HealthChecker) executeCheck(ctx context.Context, cfg CheckConfig) {
 	log := log.FromContext(ctx)
-	log.Info("firing check", "cfg", cfg)
+	log.V(1).Info("firing check", "name", cfg.Name, "url", cfg.URL)
+
+	hc.recordResult(cfg, hc.runCoalescedCheck(ctx, cfg))
+}
+
+func (hc *HealthChecker) runCheck(ctx context.Context, cfg CheckConfig) checkResult {
 	startTime := time.Now()
+	result := func(statusCode int, err error) checkResult {
+		return checkResult{start: startTime, latency: time.Since(startTime), statusCode: statusCode, err: err}
+	}
 
 	reqCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(reqCtx, "GET", cfg.URL, nil)
+	if cfg.Protocol == "tcp" {
+		return result(0, dialTCP(reqCtx, cfg.URL))
+	}
+	if cfg.Protocol == "grpc" {
+		return result(0, checkGRPC(reqCtx, cfg.URL, cfg.RequestBody))
+	}
+	if cfg.Protocol == "udp" {
+		return result(0, checkUDP(reqCtx, cfg.URL, cfg.Payload))
+	}
+	if cfg.Protocol == "icmp" {
+		return result(0, pingICMP(reqCtx, cfg.URL))
+	}
+	if cfg.Protocol == "replicas" {
+		return result(0, hc.checkReplicas(reqCtx, cfg.URL))
+	}
+
+	req, err := newCheckRequest(reqCtx, cfg)
 	if err != nil {
-		hc.recordCheckResult(cfg, startTime, 0, err)
-		return
+		return result(0, err)
+	}
+	req.Close = cfg.DisableKeepAlives
+	if cfg.Authorization != "" {
+		req.Header.Set("Authorization", cfg.Authorization)
 	}
 
-	resp, err := hc.httpClient.Do(req)
+	client, err := hc.clientFor(cfg)
 	if err != nil {
-		hc.recordCheckResult(cfg, startTime, 0, err)
-		return
+		return result(0, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result(0, err)
 	}
 	defer resp.Body.Close()
+	// The connection is only returned to the pool once the body is fully read
+	defer func() { _, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes)) }()
 
-	hc.recordCheckResult(cfg, startTime, resp.StatusCode, nil)
+	if !cfg.hasBodyExpectation() || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result(resp.StatusCode, nil)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return result(resp.StatusCode, fmt.Errorf("reading response body: %w", err))
+	}
+	return result(resp.StatusCode, checkBody(cfg, body))
+}
+
+// dialTCP checks that a TCP connection can be opened. The target is either
+// "host:port" or "tcp://host:port".
+func dialTCP(ctx context.Context, target string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", strings.TrimPrefix(target, "tcp://"))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
 }
 
 func (hc *HealthChecker) recordCheckResult(cfg CheckConfig, startTime time.Time, statusCode int, err error) {
-	namespace, service := parseTargetName(cfg.Name)
-	latency := time.Since(startTime)
+	hc.recordResult(cfg, checkResult{
+		start:      startTime,
+		latency:    time.Since(startTime),
+		statusCode: statusCode,
+		err:        err,
+	})
+}
+
+func (hc *HealthChecker) recordResult(cfg CheckConfig, result checkResult) types.HealthCheckEntry {
+	namespace, service := parseTargetName(cfg.targetName())
+	startTime := result.start
 
 	entry := types.HealthCheckEntry{
 		Timestamp:    startTime,
-		Status:       determineStatus(statusCode, err),
-		Latency:      latency,
-		Error:        formatError(err),
-		URL:          cfg.URL,
-		Method:       "GET",
-		ResponseCode: statusCode,
+		Status:       determineStatus(cfg.Protocol, result.statusCode, result.err),
+		Latency:      result.latency,
+		Error:        formatError(result.err),
+		ResponseCode: result.statusCode,
 	}
 
 	hc.mu.Lock()
 	key := namespace + "/" + service
-	info, exists := hc.healthData.Get(key)
-	if !exists {
-		info = &types.ServiceHealthInfo{
-			ServiceName: service,
-			Namespace:   namespace,
-			History:     []types.HealthCheckEntry{},
-		}
+	info := types.ServiceHealthInfo{
+		ServiceName: service,
+		Namespace:   namespace,
+	}
+	if existing, exists := hc.healthData.Get(key); exists {
+		info = *existing
+	}
+	previous := info.Status
+
+	maintenance := hc.underMaintenance(key)
+
+	// The service reports its registration's first check, so entries stay
+	// comparable with it after a probe path or method change
+	if target, ok := hc.healthTargets.Get(cfg.targetName()); ok && len(target.Checks) > 0 {
+		info.URL = target.Checks[0].URL
+		info.Method = target.Checks[0].method()
+	}
+	if info.URL == "" {
+		info.URL = cfg.URL
+		info.Method = cfg.method()
+	}
+	if cfg.URL != info.URL {
+		entry.URL = cfg.URL
+	}
+	if info.Method != cfg.method() {
+		entry.Method = cfg.method()
 	}
 
-	info.History = append(info.History, entry)
-	if len(info.History) > 100 {
-		info.History = info.History[len(info.History)-100:]
+	// Stored entries are shared with readers of GetAllHealthData, so they are
+	// replaced rather than mutated in place.
+	history := make([]types.HealthCheckEntry, 0, len(info.History)+1)
+	history = append(history, info.History...)
+	history = append(history, entry)
+	if len(history) > hc.historySize {
+		history = history[len(history)-hc.historySize:]
 	}
 
+	info.History = history
 	info.LastCheck = startTime
-	info.Status = entry.Status
-	info.URL = cfg.URL
+	info.Checks = withCheck(info.Checks, types.CheckHealth{
+		Name:      checkName(cfg),
+		URL:       entry.URL,
+		Status:    entry.Status,
+		LastCheck: startTime,
+		Latency:   entry.Latency,
+		Error:     entry.Error,
+	})
+	status := hc.serviceStatus(key, info.Checks)
+	if status != previous || info.LastTransitionTime.IsZero() {
+		info.LastTransitionTime = startTime
+	}
+	info.Status = status
 	info.Uptime = calculateUptime(info.History)
-
-	hc.healthData.Set(key, info)
+	stats := computeLatencyStats(info.History)
+	info.AvgLatency = stats.avg
+	info.P50Latency = stats.p50
+	info.P95Latency = stats.p95
+	info.P99Latency = stats.p99
+	info.CurrentStreak, info.StreakStatus = currentStreak(info.History)
+
+	hc.healthData.Set(key, &info)
+	if !maintenance {
+		hc.evaluateAlert(key, info)
+	}
 	hc.mu.Unlock()
 
+	if !maintenance {
+		hc.notifyTransition(info, previous)
+	}
+	hc.export(key, checkName(cfg), entry)
+
 	hc.notifySubscribers()
+	return entry
 }
 
 func parseTargetName(name string) (string, string) {
@@ -244,10 +525,13 @@ func parseTargetName(name string) (string, string) {
 	return "default", name
 }
 
-func determineStatus(statusCode int, err error) types.HealthStatus {
+func determineStatus(protocol string, statusCode int, err error) types.HealthStatus {
 	if err != nil {
 		return "unhealthy"
 	}
+	if protocol == "tcp" || protocol == "grpc" || protocol == "udp" || protocol == "icmp" || protocol == "replicas" {
+		return "healthy"
+	}
 	if statusCode >= 200 && statusCode < 300 {
 		return "healthy"
 	}
@@ -276,35 +560,373 @@ func calculateUptime(history []types.HealthCheckEntry) float64 {
 	return (float64(healthy) / float64(len(history))) * 100.0
 }
 
-// RegisterHealthTarget registers or updates a health target
+// currentStreak returns how many of the most recent entries share the latest
+// status, and that status
+func currentStreak(history []types.HealthCheckEntry) (int, types.HealthStatus) {
+	if len(history) == 0 {
+		return 0, ""
+	}
+
+	status := history[len(history)-1].Status
+	count := 0
+	for i := len(history) - 1; i >= 0 && history[i].Status == status; i-- {
+		count++
+	}
+	return count, status
+}
+
+type latencyStats struct {
+	avg, p50, p95, p99 time.Duration
+}
+
+// computeLatencyStats returns the mean and nearest-rank percentiles of the
+// latencies in history. History is bounded, so sorting a copy is cheap.
+func computeLatencyStats(history []types.HealthCheckEntry) latencyStats {
+	if len(history) == 0 {
+		return latencyStats{}
+	}
+
+	latencies := make([]time.Duration, len(history))
+	var total time.Duration
+	for i, entry := range history {
+		latencies[i] = entry.Latency
+		total += entry.Latency
+	}
+	slices.Sort(latencies)
+
+	percentile := func(p int) time.Duration {
+		rank := (p*len(latencies) + 99) / 100
+		return latencies[max(rank, 1)-1]
+	}
+
+	return latencyStats{
+		avg: total / time.Duration(len(latencies)),
+		p50: percentile(50),
+		p95: percentile(95),
+		p99: percentile(99),
+	}
+}
+
+// RegisterHealthTarget registers or updates a health target. A registration
+// still queued for the same name is replaced rather than queued twice.
 func (hc *HealthChecker) RegisterHealthTarget(name string, checks []CheckConfig) {
 	target := HealthTarget{
 		Name:   name,
 		Checks: checks,
 	}
-	hc.registerCh <- target
+	if !hc.queueRegistration(target) {
+		eventsCoalesced.WithLabelValues("register").Inc()
+		return
+	}
+	if !enqueue(hc, hc.registerCh, name, "register") {
+		hc.takeRegistration(name)
+	}
+}
+
+// OverrideTarget re-registers a target with a new interval and/or timeout
+// applied to all of its checks. Zero values leave the current setting in place.
+// The override lasts until the target is registered again with a different config.
+func (hc *HealthChecker) OverrideTarget(name string, interval, timeout time.Duration) error {
+	target, exists := hc.healthTargets.Get(name)
+	if !exists {
+		return ErrTargetNotFound
+	}
+
+	checks := make([]CheckConfig, len(target.Checks))
+	for i, check := range target.Checks {
+		if interval > 0 {
+			check.Interval = interval
+		}
+		if timeout > 0 {
+			check.Timeout = timeout
+		}
+		checks[i] = check
+	}
+
+	hc.RegisterHealthTarget(name, checks)
+	return nil
+}
+
+// RunCheckNow runs every check of a registered target once, outside of its
+// schedule, and records the results as if they had been ticked. Checks share
+// the target's concurrency limit with scheduled ones, and checks still running
+// when ctx is done fail. It returns ErrRateLimited when the target was
+// checked manually too often.
+func (hc *HealthChecker) RunCheckNow(ctx context.Context, name string) ([]types.HealthCheckEntry, error) {
+	target, exists := hc.healthTargets.Get(name)
+	if !exists {
+		return nil, ErrTargetNotFound
+	}
+	if !hc.manualLimiter.allow(name, time.Now()) {
+		return nil, ErrRateLimited
+	}
+
+	metadata, _ := hc.metadata.Get(name)
+	entries := make([]types.HealthCheckEntry, 0, len(target.Checks))
+	for _, check := range target.Checks {
+		check.target = name
+		release, ok := hc.targetLimiter.acquire(ctx, name, metadata.MaxConcurrentChecks)
+		if !ok {
+			return nil, ctx.Err()
+		}
+		entries = append(entries, hc.recordResult(check, hc.runCheck(ctx, check)))
+		release()
+	}
+	return entries, nil
+}
+
+// RunNamespaceChecksNow runs every target registered in namespace once, like
+// RunCheckNow, at most as many at a time as there are workers. Targets over
+// their manual check limit are reported in RateLimited; ErrRateLimited is only
+// returned when every target was. It returns ErrTargetNotFound only when no
+// target is registered in namespace, and ctx.Err() once ctx is done.
+func (hc *HealthChecker) RunNamespaceChecksNow(ctx context.Context, namespace string) (types.NamespaceChecks, error) {
+	var names []string
+	for name := range hc.healthTargets.Snapshot() {
+		if targetNamespace, _ := parseTargetName(name); targetNamespace == namespace {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return types.NamespaceChecks{}, ErrTargetNotFound
+	}
+
+	parallel := hc.workers
+	if parallel <= 0 {
+		parallel = defaultWorkers
+	}
+	slots := make(chan struct{}, parallel)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := types.NamespaceChecks{Services: make(map[string][]types.HealthCheckEntry, len(names))}
+	for _, name := range names {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return types.NamespaceChecks{}, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			entries, err := hc.RunCheckNow(ctx, name)
+			_, service := parseTargetName(name)
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, ErrRateLimited) {
+				results.RateLimited = append(results.RateLimited, service)
+			}
+			if err != nil {
+				return
+			}
+			results.Services[service] = entries
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return types.NamespaceChecks{}, err
+	}
+	slices.Sort(results.RateLimited)
+	if len(results.Services) == 0 && len(results.RateLimited) > 0 {
+		return types.NamespaceChecks{}, ErrRateLimited
+	}
+	return results, nil
 }
 
 // UnregisterHealthTarget removes a health target
 func (hc *HealthChecker) UnregisterHealthTarget(name string) {
-	hc.unregisterCh <- name
+	enqueue(hc, hc.unregisterCh, name, "unregister")
+}
+
+// GetTargets returns a copy of every registered target, sorted by name
+func (hc *HealthChecker) GetTargets() []HealthTarget {
+	snapshot := hc.healthTargets.Snapshot()
+	targets := make([]HealthTarget, 0, len(snapshot))
+	for _, target := range snapshot {
+		targets = append(targets, HealthTarget{Name: target.Name, Checks: slices.Clone(target.Checks)})
+	}
+	slices.SortFunc(targets, func(a, b HealthTarget) int { return strings.Compare(a.Name, b.Name) })
+	return targets
+}
+
+// IsRegistered reports whether a target has been registered and not since removed
+func (hc *HealthChecker) IsRegistered(name string) bool {
+	_, exists := hc.healthTargets.Get(name)
+	return exists
+}
+
+// GetHealthData returns the current health data for a single target
+func (hc *HealthChecker) GetHealthData(name string) (*types.ServiceHealthInfo, bool) {
+	namespace, service := parseTargetName(name)
+	return hc.healthData.Get(namespace + "/" + service)
+}
+
+// TargetMetadata is discovery information about a target that is not produced by its checks
+type TargetMetadata struct {
+	// Group is the user-assigned group the target is aggregated under
+	Group string
+	// Warnings are configuration problems found while discovering the target
+	Warnings []string
+	// Maintenance reports failures as maintenance and suppresses alerts and notifications
+	Maintenance bool
+	// MaxConcurrentChecks caps how many of the target's checks run at once.
+	// Zero does not limit them.
+	MaxConcurrentChecks int
+	// ExternalName is the DNS name an ExternalName service points at
+	ExternalName string
+}
+
+func (m TargetMetadata) isZero() bool {
+	return m.Group == "" && len(m.Warnings) == 0 && !m.Maintenance && m.MaxConcurrentChecks == 0 && m.ExternalName == ""
+}
+
+func (m TargetMetadata) equal(other TargetMetadata) bool {
+	return m.Group == other.Group && slices.Equal(m.Warnings, other.Warnings) &&
+		m.Maintenance == other.Maintenance && m.MaxConcurrentChecks == other.MaxConcurrentChecks &&
+		m.ExternalName == other.ExternalName
+}
+
+// SetTargetMetadata replaces the discovery metadata reported for a target.
+// Targets with warnings or an external name are reported even when they have
+// no runnable checks.
+func (hc *HealthChecker) SetTargetMetadata(name string, metadata TargetMetadata) {
+	existing, _ := hc.metadata.Get(name)
+	if existing.equal(metadata) {
+		return
+	}
+
+	if metadata.isZero() {
+		hc.metadata.Delete(name)
+		hc.notifySubscribers()
+		return
+	}
+	metadata.Warnings = slices.Clone(metadata.Warnings)
+	hc.metadata.Set(name, metadata)
+	hc.notifySubscribers()
 }
 
 // GetAllHealthData returns all current health data
 func (hc *HealthChecker) GetAllHealthData() []*types.ServiceHealthInfo {
-	keys := hc.healthData.Keys()
+	snapshot := hc.healthData.Snapshot()
+	metadata := hc.metadata.Snapshot()
+
+	for key, targetMetadata := range metadata {
+		if _, exists := snapshot[key]; exists || (len(targetMetadata.Warnings) == 0 && targetMetadata.ExternalName == "") {
+			continue
+		}
+		namespace, service := parseTargetName(key)
+		snapshot[key] = &types.ServiceHealthInfo{
+			ServiceName: service,
+			Namespace:   namespace,
+			Status:      types.HealthStatusUnknown,
+		}
+	}
+
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
 	sort.Strings(keys)
 
 	data := make([]*types.ServiceHealthInfo, 0, len(keys))
 	for _, key := range keys {
-		if info, exists := hc.healthData.Get(key); exists {
-			data = append(data, info)
+		info := snapshot[key]
+		if targetMetadata, exists := metadata[key]; exists {
+			withMetadata := *info
+			withMetadata.Group = targetMetadata.Group
+			withMetadata.Warnings = targetMetadata.Warnings
+			withMetadata.ExternalName = targetMetadata.ExternalName
+			info = &withMetadata
 		}
+		data = append(data, info)
 	}
 
 	return data
 }
 
+// GetHealthDataPage returns up to limit services starting at offset, in the
+// same order as GetAllHealthData, along with the total number of services.
+// A limit of zero returns everything after offset.
+func (hc *HealthChecker) GetHealthDataPage(offset, limit int) ([]*types.ServiceHealthInfo, int) {
+	all := hc.GetAllHealthData()
+	total := len(all)
+
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+	return all[start:end], total
+}
+
+// GetGroupHealth aggregates health across the services assigned to a group.
+// It returns false when no service belongs to the group.
+func (hc *HealthChecker) GetGroupHealth(group string) (types.GroupHealth, bool) {
+	var members []*types.ServiceHealthInfo
+	for _, info := range hc.GetAllHealthData() {
+		if info.Group == group {
+			members = append(members, info)
+		}
+	}
+	if len(members) == 0 {
+		return types.GroupHealth{}, false
+	}
+
+	summary := summarize(members)
+	status := types.HealthStatusHealthy
+	if summary.Unknown > 0 {
+		status = types.HealthStatusUnknown
+	}
+	if summary.Unhealthy > 0 {
+		status = types.HealthStatusUnhealthy
+	}
+
+	return types.GroupHealth{
+		Group:         group,
+		Status:        status,
+		HealthSummary: summary,
+	}, true
+}
+
+// GetHealthSummary returns aggregate counts and average uptime across all services
+func (hc *HealthChecker) GetHealthSummary() types.HealthSummary {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	return summarize(hc.healthData.List())
+}
+
+func summarize(infos []*types.ServiceHealthInfo) types.HealthSummary {
+	var summary types.HealthSummary
+	var totalUptime float64
+	for _, info := range infos {
+		summary.Total++
+		totalUptime += info.Uptime
+
+		switch info.Status {
+		case types.HealthStatusHealthy:
+			summary.Healthy++
+		case types.HealthStatusUnhealthy:
+			summary.Unhealthy++
+		case types.HealthStatusMaintenance:
+			summary.Maintenance++
+		default:
+			summary.Unknown++
+		}
+	}
+
+	if summary.Total == 0 {
+		return summary
+	}
+
+	summary.HealthyPercent = (float64(summary.Healthy) / float64(summary.Total)) * 100.0
+	summary.AverageUptime = totalUptime / float64(summary.Total)
+	return summary
+}
+
 // Subscribe creates a new subscription channel for health data updates
 func (hc *HealthChecker) Subscribe() chan []*types.ServiceHealthInfo {
 	hc.subMu.Lock()
@@ -315,23 +937,59 @@ func (hc *HealthChecker) Subscribe() chan []*types.ServiceHealthInfo {
 	return ch
 }
 
-// Unsubscribe removes a subscription channel
+// Unsubscribe removes a subscription channel and closes it. Channels that are
+// not subscribed, e.g. ones already unsubscribed, are left alone.
 func (hc *HealthChecker) Unsubscribe(ch chan []*types.ServiceHealthInfo) {
 	hc.subMu.Lock()
 	defer hc.subMu.Unlock()
 
+	if !hc.subscribers[ch] {
+		return
+	}
 	delete(hc.subscribers, ch)
 	close(ch)
 }
 
-// notifySubscribers sends current health data to all subscribers
+// notifySubscribers sends current health data to all subscribers. With a
+// notify debounce, changes within the window are coalesced into one update
+// that is built when the window closes, so it always reflects the final state.
 func (hc *HealthChecker) notifySubscribers() {
+	if hc.notifyDebounce <= 0 {
+		hc.publish()
+		return
+	}
+
+	hc.notifyMu.Lock()
+	defer hc.notifyMu.Unlock()
+	if hc.notifyPending {
+		return
+	}
+	hc.notifyPending = true
+	time.AfterFunc(hc.notifyDebounce, func() {
+		hc.notifyMu.Lock()
+		hc.notifyPending = false
+		hc.notifyMu.Unlock()
+		hc.publish()
+	})
+}
+
+func (hc *HealthChecker) publish() {
 	hc.subMu.RLock()
 	defer hc.subMu.RUnlock()
 
 	data := hc.GetAllHealthData()
 
 	for ch := range hc.subscribers {
+		select {
+		case ch <- data:
+			continue
+		default:
+		}
+		// Replace an update the subscriber has not read yet so it is never left on stale data
+		select {
+		case <-ch:
+		default:
+		}
 		select {
 		case ch <- data:
 		default:
@@ -0,0 +1,1150 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/healthcheck/mocks"
+	"github.com/kdwils/constellation/internal/types"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthChecker_GetAllHealthDataConcurrent(t *testing.T) {
+	hc := NewHealthChecker()
+	cfg := CheckConfig{
+		Name: "default/api",
+		URL:  "http://api.default.svc.cluster.local:8080/health",
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			var err error
+			if i%2 == 0 {
+				err = errors.New("connection refused")
+			}
+			hc.recordCheckResult(cfg, time.Now(), 200, err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for range 200 {
+			for _, info := range hc.GetAllHealthData() {
+				if info.URL != cfg.URL {
+					t.Errorf("TestHealthChecker_GetAllHealthDataConcurrent() url = %v, want %v", info.URL, cfg.URL)
+				}
+				for _, entry := range info.History {
+					if entry.URL != "" {
+						t.Errorf("TestHealthChecker_GetAllHealthDataConcurrent() entry url = %v, want empty", entry.URL)
+					}
+				}
+				if info.Uptime < 0 || info.Uptime > 100 {
+					t.Errorf("TestHealthChecker_GetAllHealthDataConcurrent() uptime = %v out of range", info.Uptime)
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	data := hc.GetAllHealthData()
+	if len(data) != 1 {
+		t.Fatalf("TestHealthChecker_GetAllHealthDataConcurrent() entries = %v, want 1", len(data))
+	}
+	if len(data[0].History) != 100 {
+		t.Errorf("TestHealthChecker_GetAllHealthDataConcurrent() history length = %v, want 100", len(data[0].History))
+	}
+}
+
+func TestHealthChecker_GetHealthSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []*types.ServiceHealthInfo
+		want types.HealthSummary
+	}{
+		{
+			name: "no services",
+			want: types.HealthSummary{},
+		},
+		{
+			name: "all healthy",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 100},
+				{ServiceName: "web", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 90},
+			},
+			want: types.HealthSummary{
+				Total:          2,
+				Healthy:        2,
+				HealthyPercent: 100,
+				AverageUptime:  95,
+			},
+		},
+		{
+			name: "mixed",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 100},
+				{ServiceName: "web", Namespace: "default", Status: types.HealthStatusUnhealthy, Uptime: 20},
+				{ServiceName: "db", Namespace: "data", Status: types.HealthStatusUnknown, Uptime: 0},
+				{ServiceName: "cache", Namespace: "data", Status: types.HealthStatusHealthy, Uptime: 80},
+			},
+			want: types.HealthSummary{
+				Total:          4,
+				Healthy:        2,
+				Unhealthy:      1,
+				Unknown:        1,
+				HealthyPercent: 50,
+				AverageUptime:  50,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			for _, info := range tt.data {
+				hc.healthData.Set(info.Namespace+"/"+info.ServiceName, info)
+			}
+
+			got := hc.GetHealthSummary()
+			if got != tt.want {
+				t.Errorf("GetHealthSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_GetHealthDataPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		offset    int
+		limit     int
+		wantNames []string
+	}{
+		{
+			name:      "first page",
+			offset:    0,
+			limit:     2,
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name:      "last partial page",
+			offset:    2,
+			limit:     2,
+			wantNames: []string{"c"},
+		},
+		{
+			name:      "offset past the end",
+			offset:    5,
+			limit:     2,
+			wantNames: []string{},
+		},
+		{
+			name:      "zero limit returns the rest",
+			offset:    1,
+			wantNames: []string{"b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			for _, name := range []string{"c", "a", "b"} {
+				hc.healthData.Set("default/"+name, &types.ServiceHealthInfo{ServiceName: name, Namespace: "default"})
+			}
+
+			items, total := hc.GetHealthDataPage(tt.offset, tt.limit)
+			if total != 3 {
+				t.Errorf("GetHealthDataPage() total = %v, want 3", total)
+			}
+			gotNames := make([]string, 0, len(items))
+			for _, item := range items {
+				gotNames = append(gotNames, item.ServiceName)
+			}
+			if !slices.Equal(gotNames, tt.wantNames) {
+				t.Errorf("GetHealthDataPage() names = %v, want %v", gotNames, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestComputeLatencyStats(t *testing.T) {
+	hundred := make([]types.HealthCheckEntry, 100)
+	for i := range hundred {
+		hundred[len(hundred)-1-i].Latency = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		name    string
+		history []types.HealthCheckEntry
+		want    latencyStats
+	}{
+		{
+			name: "no history",
+		},
+		{
+			name:    "single entry",
+			history: []types.HealthCheckEntry{{Latency: 20 * time.Millisecond}},
+			want: latencyStats{
+				avg: 20 * time.Millisecond,
+				p50: 20 * time.Millisecond,
+				p95: 20 * time.Millisecond,
+				p99: 20 * time.Millisecond,
+			},
+		},
+		{
+			name:    "1ms to 100ms",
+			history: hundred,
+			want: latencyStats{
+				avg: 50500 * time.Microsecond,
+				p50: 50 * time.Millisecond,
+				p95: 95 * time.Millisecond,
+				p99: 99 * time.Millisecond,
+			},
+		},
+		{
+			name: "one slow outlier",
+			history: []types.HealthCheckEntry{
+				{Latency: 10 * time.Millisecond},
+				{Latency: 10 * time.Millisecond},
+				{Latency: 10 * time.Millisecond},
+				{Latency: 970 * time.Millisecond},
+			},
+			want: latencyStats{
+				avg: 250 * time.Millisecond,
+				p50: 10 * time.Millisecond,
+				p95: 970 * time.Millisecond,
+				p99: 970 * time.Millisecond,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeLatencyStats(tt.history); got != tt.want {
+				t.Errorf("computeLatencyStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	entries := func(statuses ...types.HealthStatus) []types.HealthCheckEntry {
+		history := make([]types.HealthCheckEntry, len(statuses))
+		for i, status := range statuses {
+			history[i].Status = status
+		}
+		return history
+	}
+
+	tests := []struct {
+		name       string
+		history    []types.HealthCheckEntry
+		wantCount  int
+		wantStatus types.HealthStatus
+	}{
+		{
+			name: "no history",
+		},
+		{
+			name:       "all healthy",
+			history:    entries("healthy", "healthy", "healthy"),
+			wantCount:  3,
+			wantStatus: "healthy",
+		},
+		{
+			name:       "all unhealthy",
+			history:    entries("unhealthy", "unhealthy"),
+			wantCount:  2,
+			wantStatus: "unhealthy",
+		},
+		{
+			name:       "alternating",
+			history:    entries("healthy", "unhealthy", "healthy", "unhealthy"),
+			wantCount:  1,
+			wantStatus: "unhealthy",
+		},
+		{
+			name:       "recovered after failures",
+			history:    entries("unhealthy", "unhealthy", "healthy", "healthy"),
+			wantCount:  2,
+			wantStatus: "healthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, status := currentStreak(tt.history)
+			if count != tt.wantCount {
+				t.Errorf("currentStreak() count = %v, want %v", count, tt.wantCount)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("currentStreak() status = %v, want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RecordResultLatencyStats(t *testing.T) {
+	hc := NewHealthChecker()
+	cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+	for _, latency := range []time.Duration{30, 10, 20} {
+		hc.recordResult(cfg, checkResult{start: time.Now(), latency: latency * time.Millisecond, statusCode: http.StatusOK})
+	}
+
+	info, ok := hc.GetHealthData("default/api")
+	if !ok {
+		t.Fatalf("GetHealthData() ok = false, want true")
+	}
+	if info.AvgLatency != 20*time.Millisecond {
+		t.Errorf("AvgLatency = %v, want 20ms", info.AvgLatency)
+	}
+	if info.P50Latency != 20*time.Millisecond {
+		t.Errorf("P50Latency = %v, want 20ms", info.P50Latency)
+	}
+	if info.P99Latency != 30*time.Millisecond {
+		t.Errorf("P99Latency = %v, want 30ms", info.P99Latency)
+	}
+	if info.CurrentStreak != 3 || info.StreakStatus != types.HealthStatusHealthy {
+		t.Errorf("streak = %v %v, want 3 healthy", info.CurrentStreak, info.StreakStatus)
+	}
+}
+
+func TestHealthChecker_WithHistorySize(t *testing.T) {
+	tests := []struct {
+		name        string
+		historySize int
+		checks      int
+		wantLen     int
+	}{
+		{
+			name:        "trimmed to configured size",
+			historySize: 3,
+			checks:      5,
+			wantLen:     3,
+		},
+		{
+			name:        "shorter history is kept whole",
+			historySize: 3,
+			checks:      2,
+			wantLen:     2,
+		},
+		{
+			name:        "invalid size keeps the default",
+			historySize: 0,
+			checks:      105,
+			wantLen:     defaultHistorySize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithHistorySize(tt.historySize))
+			cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+			start := time.Now()
+			for i := range tt.checks {
+				hc.recordResult(cfg, checkResult{start: start.Add(time.Duration(i) * time.Second), statusCode: http.StatusOK})
+			}
+
+			info, ok := hc.GetHealthData("default/api")
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if len(info.History) != tt.wantLen {
+				t.Fatalf("History length = %v, want %v", len(info.History), tt.wantLen)
+			}
+			wantLast := start.Add(time.Duration(tt.checks-1) * time.Second)
+			if !info.History[len(info.History)-1].Timestamp.Equal(wantLast) {
+				t.Errorf("last entry = %v, want %v", info.History[len(info.History)-1].Timestamp, wantLast)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_ConnectionReuse(t *testing.T) {
+	tests := []struct {
+		name              string
+		disableKeepAlives bool
+		checks            int
+		wantConnections   int64
+	}{
+		{
+			name:            "connections are reused by default",
+			checks:          3,
+			wantConnections: 1,
+		},
+		{
+			name:              "keepalives disabled per check",
+			disableKeepAlives: true,
+			checks:            3,
+			wantConnections:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connections atomic.Int64
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					connections.Add(1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:              "default/api",
+				URL:               server.URL,
+				Timeout:           time.Second,
+				DisableKeepAlives: tt.disableKeepAlives,
+			}
+			for range tt.checks {
+				hc.executeCheck(context.Background(), cfg)
+			}
+
+			if got := connections.Load(); got != tt.wantConnections {
+				t.Errorf("executeCheck() opened %v connections, want %v", got, tt.wantConnections)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_TCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus types.HealthStatus
+	}{
+		{
+			name:       "listening port is healthy",
+			url:        "tcp://" + listener.Addr().String(),
+			wantStatus: "healthy",
+		},
+		{
+			name:       "closed port is unhealthy",
+			url:        "tcp://" + closedAddr,
+			wantStatus: "unhealthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:     "default/db",
+				URL:      tt.url,
+				Timeout:  time.Second,
+				Protocol: "tcp",
+			}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData(cfg.Name)
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RecordCheckResultInterning(t *testing.T) {
+	primary := CheckConfig{Name: "default/api", URL: "http://api.default.svc.cluster.local:80/healthz"}
+	secondary := CheckConfig{Name: "default/api", URL: "http://api.default.svc.cluster.local:9090/ready"}
+	startTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		checks   []CheckConfig
+		wantURLs []string
+	}{
+		{
+			name:     "single check stores url once",
+			checks:   []CheckConfig{primary, primary, primary},
+			wantURLs: []string{"", "", ""},
+		},
+		{
+			name:     "additional checks keep their own url",
+			checks:   []CheckConfig{primary, secondary, primary},
+			wantURLs: []string{"", secondary.URL, ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			for i, cfg := range tt.checks {
+				hc.recordCheckResult(cfg, startTime.Add(time.Duration(i)*time.Second), 200, nil)
+			}
+
+			info, _ := hc.healthData.Get("default/api")
+			if info.URL != primary.URL {
+				t.Errorf("recordCheckResult() info url = %v, want %v", info.URL, primary.URL)
+			}
+			if info.Method != http.MethodGet {
+				t.Errorf("recordCheckResult() info method = %v, want %v", info.Method, http.MethodGet)
+			}
+
+			for i, entry := range info.History {
+				if entry.URL != tt.wantURLs[i] {
+					t.Errorf("recordCheckResult() entry %d url = %q, want %q", i, entry.URL, tt.wantURLs[i])
+				}
+				if entry.Method != "" {
+					t.Errorf("recordCheckResult() entry %d method = %q, want empty", i, entry.Method)
+				}
+
+				resolvedURL := entry.URL
+				if resolvedURL == "" {
+					resolvedURL = info.URL
+				}
+				if resolvedURL != tt.checks[i].URL {
+					t.Errorf("recordCheckResult() entry %d resolves to %v, want %v", i, resolvedURL, tt.checks[i].URL)
+				}
+			}
+
+			for _, check := range info.Checks {
+				wantURL := check.Name
+				if check.Name == info.URL {
+					wantURL = ""
+				}
+				if check.URL != wantURL {
+					t.Errorf("recordCheckResult() check %s url = %q, want %q", check.Name, check.URL, wantURL)
+				}
+			}
+
+			// Discovered checks are named by URL, so each check names it once
+			// more regardless of how long the history is
+			withoutChecks := *info
+			withoutChecks.Checks = nil
+			encoded, err := json.Marshal(withoutChecks)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if got := strings.Count(string(encoded), primary.URL); got != 1 {
+				t.Errorf("recordCheckResult() primary url encoded %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RecordCheckResultFollowsRegistration(t *testing.T) {
+	original := CheckConfig{Name: "default/api", URL: "http://api.default.svc.cluster.local:80/healthz"}
+	changed := CheckConfig{Name: "default/api", URL: "http://api.default.svc.cluster.local:80/livez", Method: http.MethodHead}
+	startTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	hc := NewHealthChecker()
+	hc.healthTargets.Set("default/api", HealthTarget{Name: "default/api", Checks: []CheckConfig{original}})
+	hc.recordCheckResult(original, startTime, 200, nil)
+
+	hc.healthTargets.Set("default/api", HealthTarget{Name: "default/api", Checks: []CheckConfig{changed}})
+	hc.recordCheckResult(changed, startTime.Add(time.Second), 200, nil)
+
+	info, _ := hc.healthData.Get("default/api")
+	if info.URL != changed.URL {
+		t.Errorf("recordCheckResult() info url = %v, want %v", info.URL, changed.URL)
+	}
+	if info.Method != http.MethodHead {
+		t.Errorf("recordCheckResult() info method = %v, want %v", info.Method, http.MethodHead)
+	}
+	latest := info.History[len(info.History)-1]
+	if latest.URL != "" || latest.Method != "" {
+		t.Errorf("recordCheckResult() latest entry = %q %q, want both empty", latest.Method, latest.URL)
+	}
+}
+
+func TestHealthChecker_OverrideTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		interval     time.Duration
+		timeout      time.Duration
+		wantErr      error
+		wantInterval time.Duration
+		wantTimeout  time.Duration
+	}{
+		{
+			name:         "overrides interval",
+			target:       "default/api",
+			interval:     30 * time.Second,
+			wantInterval: 30 * time.Second,
+			wantTimeout:  time.Second,
+		},
+		{
+			name:         "overrides timeout",
+			target:       "default/api",
+			timeout:      5 * time.Second,
+			wantInterval: time.Hour,
+			wantTimeout:  5 * time.Second,
+		},
+		{
+			name:    "unknown target",
+			target:  "default/missing",
+			wantErr: ErrTargetNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockHTTPClient(ctrl)
+			client.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil).AnyTimes()
+
+			hc := NewHealthChecker(WithHTTPClient(client))
+			go hc.Start(ctx)
+
+			original := []CheckConfig{{Name: "default/api", URL: "http://api", Interval: time.Hour, Timeout: time.Second}}
+			hc.RegisterHealthTarget("default/api", original)
+			waitForTarget(t, hc, "default/api", func(target HealthTarget) bool { return len(target.Checks) == 1 })
+
+			err := hc.OverrideTarget(tt.target, tt.interval, tt.timeout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OverrideTarget() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			waitForTarget(t, hc, "default/api", func(target HealthTarget) bool {
+				return target.Checks[0].Interval == tt.wantInterval && target.Checks[0].Timeout == tt.wantTimeout
+			})
+
+			hc.RegisterHealthTarget("default/api", original)
+			waitForTarget(t, hc, "default/api", func(target HealthTarget) bool {
+				return target.Checks[0].Interval == time.Hour && target.Checks[0].Timeout == time.Second
+			})
+		})
+	}
+}
+
+func TestHealthChecker_RunCheckNow(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		statusCode int
+		wantErr    error
+		wantStatus types.HealthStatus
+	}{
+		{
+			name:       "healthy check",
+			target:     "default/api",
+			statusCode: http.StatusOK,
+			wantStatus: "healthy",
+		},
+		{
+			name:       "failing check",
+			target:     "default/api",
+			statusCode: http.StatusServiceUnavailable,
+			wantStatus: "unhealthy",
+		},
+		{
+			name:    "unknown target",
+			target:  "default/missing",
+			wantErr: ErrTargetNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockHTTPClient(ctrl)
+			client.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: tt.statusCode, Body: http.NoBody}, nil).AnyTimes()
+
+			hc := NewHealthChecker(WithHTTPClient(client))
+			hc.healthTargets.Set("default/api", HealthTarget{
+				Name:   "default/api",
+				Checks: []CheckConfig{{Name: "default/api", URL: "http://api", Interval: time.Hour, Timeout: time.Second}},
+			})
+
+			entries, err := hc.RunCheckNow(context.Background(), tt.target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunCheckNow() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("RunCheckNow() entries = %v, want 1", len(entries))
+			}
+			if entries[0].Status != tt.wantStatus {
+				t.Errorf("RunCheckNow() status = %v, want %v", entries[0].Status, tt.wantStatus)
+			}
+			if entries[0].ResponseCode != tt.statusCode {
+				t.Errorf("RunCheckNow() response code = %v, want %v", entries[0].ResponseCode, tt.statusCode)
+			}
+
+			info, ok := hc.GetHealthData(tt.target)
+			if !ok || info.Status != tt.wantStatus {
+				t.Errorf("GetHealthData() = %v, %v, want status %v", info, ok, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RunCheckNowCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	hc := NewHealthChecker()
+	hc.healthTargets.Set("default/api", HealthTarget{
+		Name:   "default/api",
+		Checks: []CheckConfig{{Name: "default/api", URL: server.URL, Interval: time.Hour, Timeout: 10 * time.Second}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	entries, err := hc.RunCheckNow(ctx, "default/api")
+	if err != nil {
+		t.Fatalf("RunCheckNow() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunCheckNow() took %v after its context was done", elapsed)
+	}
+	if entries[0].Status != types.HealthStatusUnhealthy {
+		t.Errorf("RunCheckNow() status = %v, want %v", entries[0].Status, types.HealthStatusUnhealthy)
+	}
+}
+
+func waitForTarget(t *testing.T, hc *HealthChecker, name string, ready func(HealthTarget) bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		target, ok := hc.healthTargets.Get(name)
+		if ok && ready(target) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("target %s did not reach expected state", name)
+}
+
+func TestHealthChecker_SetTargetMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		recorded     bool
+		warnings     []string
+		wantServices int
+		wantStatus   types.HealthStatus
+	}{
+		{
+			name:         "warnings attach to checked service",
+			recorded:     true,
+			warnings:     []string{"probe port 9090 is not exposed"},
+			wantServices: 1,
+			wantStatus:   types.HealthStatusHealthy,
+		},
+		{
+			name:         "warnings surface a service without checks",
+			warnings:     []string{"probe port 9090 is not exposed"},
+			wantServices: 1,
+			wantStatus:   types.HealthStatusUnknown,
+		},
+		{
+			name:         "no warnings and no checks",
+			wantServices: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			if tt.recorded {
+				hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api"}, time.Now(), http.StatusOK, nil)
+			}
+			hc.SetTargetMetadata("default/api", TargetMetadata{Warnings: tt.warnings})
+
+			data := hc.GetAllHealthData()
+			if len(data) != tt.wantServices {
+				t.Fatalf("GetAllHealthData() services = %v, want %v", len(data), tt.wantServices)
+			}
+			if tt.wantServices == 0 {
+				return
+			}
+			if data[0].Status != tt.wantStatus {
+				t.Errorf("GetAllHealthData() status = %v, want %v", data[0].Status, tt.wantStatus)
+			}
+			if !slices.Equal(data[0].Warnings, tt.warnings) {
+				t.Errorf("GetAllHealthData() warnings = %v, want %v", data[0].Warnings, tt.warnings)
+			}
+
+			hc.SetTargetMetadata("default/api", TargetMetadata{})
+			for _, info := range hc.GetAllHealthData() {
+				if len(info.Warnings) != 0 {
+					t.Errorf("GetAllHealthData() warnings after clear = %v, want none", info.Warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthChecker_ExternalNameTarget(t *testing.T) {
+	hc := NewHealthChecker()
+	hc.SetTargetMetadata("default/billing", TargetMetadata{ExternalName: "billing.example.com"})
+
+	data := hc.GetAllHealthData()
+	if len(data) != 1 {
+		t.Fatalf("GetAllHealthData() services = %v, want 1", len(data))
+	}
+	if data[0].ExternalName != "billing.example.com" {
+		t.Errorf("GetAllHealthData() external name = %q, want billing.example.com", data[0].ExternalName)
+	}
+	if data[0].Status != types.HealthStatusUnknown {
+		t.Errorf("GetAllHealthData() status = %v, want %v", data[0].Status, types.HealthStatusUnknown)
+	}
+}
+
+func TestHealthChecker_GetGroupHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    map[string][]int
+		groups     map[string]string
+		group      string
+		wantFound  bool
+		wantStatus types.HealthStatus
+		wantTotal  int
+		wantUptime float64
+	}{
+		{
+			name:       "rollup of two services",
+			results:    map[string][]int{"payments/api": {200, 200}, "payments/worker": {200, 500}, "search/api": {500}},
+			groups:     map[string]string{"payments/api": "payments", "payments/worker": "payments", "search/api": "search"},
+			group:      "payments",
+			wantFound:  true,
+			wantStatus: types.HealthStatusUnhealthy,
+			wantTotal:  2,
+			wantUptime: 75,
+		},
+		{
+			name:       "all members healthy",
+			results:    map[string][]int{"payments/api": {200}, "payments/worker": {200}},
+			groups:     map[string]string{"payments/api": "payments", "payments/worker": "payments"},
+			group:      "payments",
+			wantFound:  true,
+			wantStatus: types.HealthStatusHealthy,
+			wantTotal:  2,
+			wantUptime: 100,
+		},
+		{
+			name:    "unknown group",
+			results: map[string][]int{"payments/api": {200}},
+			groups:  map[string]string{"payments/api": "payments"},
+			group:   "search",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			for name, codes := range tt.results {
+				for _, code := range codes {
+					hc.recordCheckResult(CheckConfig{Name: name, URL: "http://" + name}, time.Now(), code, nil)
+				}
+			}
+			for name, group := range tt.groups {
+				hc.SetTargetMetadata(name, TargetMetadata{Group: group})
+			}
+
+			got, found := hc.GetGroupHealth(tt.group)
+			if found != tt.wantFound {
+				t.Fatalf("GetGroupHealth() found = %v, want %v", found, tt.wantFound)
+			}
+			if !tt.wantFound {
+				return
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("GetGroupHealth() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if got.Total != tt.wantTotal {
+				t.Errorf("GetGroupHealth() total = %v, want %v", got.Total, tt.wantTotal)
+			}
+			if got.AverageUptime != tt.wantUptime {
+				t.Errorf("GetGroupHealth() average uptime = %v, want %v", got.AverageUptime, tt.wantUptime)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_NotifyDebounce(t *testing.T) {
+	tests := []struct {
+		name              string
+		debounce          time.Duration
+		events            int
+		wantNotifications int
+	}{
+		{
+			name:              "burst is coalesced into one notification",
+			debounce:          50 * time.Millisecond,
+			events:            50,
+			wantNotifications: 1,
+		},
+		{
+			name:              "without debounce every event notifies",
+			events:            3,
+			wantNotifications: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithNotifyDebounce(tt.debounce))
+			ch := hc.Subscribe()
+			defer hc.Unsubscribe(ch)
+
+			var received [][]*types.ServiceHealthInfo
+			for i := range tt.events {
+				hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api"}, time.Now(), http.StatusOK, nil)
+				if tt.debounce > 0 {
+					continue
+				}
+				select {
+				case data := <-ch:
+					received = append(received, data)
+				case <-time.After(time.Second):
+					t.Fatalf("notifySubscribers() no notification for event %d", i)
+				}
+			}
+
+			if tt.debounce > 0 {
+				deadline := time.After(4 * tt.debounce)
+			collect:
+				for {
+					select {
+					case data := <-ch:
+						received = append(received, data)
+					case <-deadline:
+						break collect
+					}
+				}
+			}
+
+			if len(received) != tt.wantNotifications {
+				t.Fatalf("notifySubscribers() notifications = %v, want %v", len(received), tt.wantNotifications)
+			}
+			final := received[len(received)-1]
+			if len(final) != 1 {
+				t.Fatalf("notifySubscribers() final state has %v services, want 1", len(final))
+			}
+			if len(final[0].History) != tt.events {
+				t.Errorf("notifySubscribers() final state has %v entries, want %v", len(final[0].History), tt.events)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_NotifyReplacesUnreadUpdate(t *testing.T) {
+	hc := NewHealthChecker()
+	ch := hc.Subscribe()
+	defer hc.Unsubscribe(ch)
+
+	for range 3 {
+		hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api"}, time.Now(), http.StatusOK, nil)
+	}
+
+	data := <-ch
+	if len(data) != 1 {
+		t.Fatalf("notifySubscribers() pending update has %v services, want 1", len(data))
+	}
+	if len(data[0].History) != 3 {
+		t.Errorf("notifySubscribers() pending update has %v entries, want the latest with 3", len(data[0].History))
+	}
+}
+
+func TestHealthChecker_PublishDoesNotBlockOnIdleSubscriber(t *testing.T) {
+	hc := NewHealthChecker()
+	idle := hc.Subscribe()
+	defer hc.Unsubscribe(idle)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10 {
+			hc.publish()
+			// Subscribe takes the write lock, so it would wait forever on a publish stuck holding the read lock
+			hc.Unsubscribe(hc.Subscribe())
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("publish() blocked on a subscriber that never reads")
+	}
+}
+
+func TestHealthChecker_NotifyAfterUnsubscribe(t *testing.T) {
+	hc := NewHealthChecker()
+	gone := hc.Subscribe()
+	hc.Unsubscribe(gone)
+	// A second Unsubscribe, e.g. from shutdown racing a handler's deferred
+	// cleanup, must not close the channel again
+	hc.Unsubscribe(gone)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10 {
+			hc.notifySubscribers()
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("notifySubscribers() blocked after the subscriber went away")
+	}
+	if _, open := <-gone; open {
+		t.Errorf("unsubscribed channel received an update, want it closed")
+	}
+}
+
+func TestHealthChecker_Running(t *testing.T) {
+	hc := NewHealthChecker()
+	if hc.Running() {
+		t.Errorf("Running() before Start = true, want false")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = hc.Start(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !hc.Running() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !hc.Running() {
+		t.Errorf("Running() after Start = false, want true")
+	}
+
+	cancel()
+	<-done
+	if hc.Running() {
+		t.Errorf("Running() after stop = true, want false")
+	}
+}
+
+func TestHealthChecker_GetTargetsReturnsCopy(t *testing.T) {
+	hc := NewHealthChecker()
+	hc.healthTargets.Set("default/web", HealthTarget{
+		Name:   "default/web",
+		Checks: []CheckConfig{{Name: "default/web", URL: "http://web"}},
+		cancel: func() {},
+	})
+	hc.healthTargets.Set("default/api", HealthTarget{
+		Name:   "default/api",
+		Checks: []CheckConfig{{Name: "default/api", URL: "http://api"}},
+	})
+
+	targets := hc.GetTargets()
+	if len(targets) != 2 || targets[0].Name != "default/api" || targets[1].Name != "default/web" {
+		t.Fatalf("GetTargets() = %+v, want default/api and default/web in order", targets)
+	}
+	if targets[1].cancel != nil {
+		t.Errorf("GetTargets() leaked the cancel func")
+	}
+
+	targets[0].Checks[0].URL = "http://changed"
+	stored, _ := hc.healthTargets.Get("default/api")
+	if stored.Checks[0].URL != "http://api" {
+		t.Errorf("GetTargets() shares checks with the registry, URL = %v", stored.Checks[0].URL)
+	}
+}
+
+func TestHealthChecker_SendsAuthorization(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{name: "without credentials"},
+		{name: "with credentials", authorization: "Bearer abc123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockHTTPClient(ctrl)
+			client.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				if got := req.Header.Get("Authorization"); got != tt.authorization {
+					t.Errorf("Authorization header = %q, want %q", got, tt.authorization)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+
+			hc := NewHealthChecker(WithHTTPClient(client))
+			hc.runCheck(context.Background(), CheckConfig{
+				Name: "default/api", URL: "http://api/healthz", Timeout: time.Second, Protocol: "http",
+				Authorization: tt.authorization,
+			})
+		})
+	}
+}
+
+func TestHealthChecker_LastTransitionTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []int
+		want    int
+	}{
+		{name: "first check sets the time", results: []int{200}, want: 0},
+		{name: "same status keeps the time", results: []int{200, 200, 200}, want: 0},
+		{name: "status change moves the time", results: []int{200, 200, 500, 500}, want: 2},
+		{name: "recovery moves the time", results: []int{500, 200, 200}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+			start := time.Now()
+			for i, code := range tt.results {
+				hc.recordCheckResult(cfg, start.Add(time.Duration(i)*time.Minute), code, nil)
+			}
+
+			info, ok := hc.GetHealthData("default/api")
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			want := start.Add(time.Duration(tt.want) * time.Minute)
+			if !info.LastTransitionTime.Equal(want) {
+				t.Errorf("LastTransitionTime = %v, want %v", info.LastTransitionTime, want)
+			}
+		})
+	}
+}
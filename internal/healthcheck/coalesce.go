@@ -0,0 +1,96 @@
+package healthcheck
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkResult is the outcome of a single request against a check URL
+type checkResult struct {
+	start      time.Time
+	latency    time.Duration
+	statusCode int
+	err        error
+}
+
+// pendingCheck is a request shared by every check of the same URL within the coalescing window
+type pendingCheck struct {
+	startedAt time.Time
+	done      chan struct{}
+	result    checkResult
+}
+
+// checkCoalescer executes checks against the same URL once per window and
+// hands the result to every target that asked for it
+type checkCoalescer struct {
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[string]*pendingCheck
+}
+
+// WithCheckCoalescing shares one request between checks of the same URL that
+// fire within window of each other, e.g. services fronting a shared backend
+func WithCheckCoalescing(window time.Duration) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		if window <= 0 {
+			hc.coalescer = nil
+			return
+		}
+		hc.coalescer = &checkCoalescer{
+			window:  window,
+			pending: make(map[string]*pendingCheck),
+		}
+	}
+}
+
+// do returns the result of a request for key made within the window, running
+// check only when there is none
+func (c *checkCoalescer) do(key string, check func() checkResult) checkResult {
+	c.mu.Lock()
+	if p, exists := c.pending[key]; exists && time.Since(p.startedAt) < c.window {
+		c.mu.Unlock()
+		<-p.done
+		return p.result
+	}
+
+	p := &pendingCheck{startedAt: time.Now(), done: make(chan struct{})}
+	c.pending[key] = p
+	c.mu.Unlock()
+
+	p.result = check()
+	close(p.done)
+
+	time.AfterFunc(c.window, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.pending[key] == p {
+			delete(c.pending, key)
+		}
+	})
+
+	return p.result
+}
+
+func (hc *HealthChecker) runCoalescedCheck(ctx context.Context, cfg CheckConfig) checkResult {
+	if hc.coalescer == nil {
+		return hc.runCheck(ctx, cfg)
+	}
+	return hc.coalescer.do(coalesceKey(cfg), func() checkResult {
+		return hc.runCheck(ctx, cfg)
+	})
+}
+
+// coalesceKey groups checks by URL. Checks only share a result with checks
+// that send the same request, verify the server the same way, wait as long for
+// it and judge the response the same way.
+func coalesceKey(cfg CheckConfig) string {
+	return strings.Join([]string{
+		cfg.Protocol, cfg.URL, cfg.method(), cfg.RequestBody, cfg.ContentType, cfg.Authorization, cfg.Payload,
+		cfg.Timeout.String(), strconv.FormatBool(cfg.InsecureSkipVerify), cfg.CABundle,
+		strconv.FormatBool(cfg.DisableKeepAlives),
+		cfg.ExpectBodyContains, cfg.ExpectJSONPath, cfg.ExpectJSONValue,
+	}, "\x00")
+}
@@ -0,0 +1,102 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_CheckCoalescing(t *testing.T) {
+	tests := []struct {
+		name         string
+		window       time.Duration
+		services     []string
+		wantRequests int64
+	}{
+		{
+			name:         "disabled by default",
+			services:     []string{"default/api", "default/api-canary"},
+			wantRequests: 2,
+		},
+		{
+			name:         "shared URL is fetched once",
+			window:       time.Minute,
+			services:     []string{"default/api", "default/api-canary", "other/api"},
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				<-release
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			var opts []HealthCheckerOpt
+			if tt.window > 0 {
+				opts = append(opts, WithCheckCoalescing(tt.window))
+			}
+			hc := NewHealthChecker(opts...)
+
+			var wg sync.WaitGroup
+			for _, service := range tt.services {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					hc.executeCheck(context.Background(), CheckConfig{Name: service, URL: server.URL, Timeout: time.Second})
+				}()
+			}
+
+			// Let every check reach the server or join the in-flight request
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("executeCheck() requests = %v, want %v", got, tt.wantRequests)
+			}
+			for _, service := range tt.services {
+				info, ok := hc.healthData.Get(service)
+				if !ok {
+					t.Errorf("executeCheck() no result recorded for %s", service)
+					continue
+				}
+				if len(info.History) != 1 || info.Status != "healthy" {
+					t.Errorf("executeCheck() %s history = %v status = %v, want 1 healthy result",
+						service, len(info.History), info.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthChecker_CheckCoalescingWindow(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker(WithCheckCoalescing(20 * time.Millisecond))
+	cfg := CheckConfig{Name: "default/api", URL: server.URL, Timeout: time.Second}
+
+	hc.executeCheck(context.Background(), cfg)
+	hc.executeCheck(context.Background(), CheckConfig{Name: "default/api-canary", URL: server.URL, Timeout: time.Second})
+	if got := requests.Load(); got != 1 {
+		t.Errorf("executeCheck() requests within window = %v, want 1", got)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	hc.executeCheck(context.Background(), cfg)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("executeCheck() requests after window = %v, want 2", got)
+	}
+}
@@ -0,0 +1,89 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+)
+
+// targetLimiter caps how many checks of one target run at once, for services
+// with many pods behind a single instance that would be overwhelmed by every
+// check firing on the same tick
+type targetLimiter struct {
+	mu    sync.Mutex
+	slots map[string]targetSlots
+}
+
+type targetSlots struct {
+	limit int
+	ch    chan struct{}
+}
+
+func newTargetLimiter() *targetLimiter {
+	return &targetLimiter{slots: make(map[string]targetSlots)}
+}
+
+// acquire waits for one of limit slots of key. The returned release must be
+// called once the check is done. A limit below one does not wait. It returns
+// false if ctx is done first.
+func (l *targetLimiter) acquire(ctx context.Context, key string, limit int) (func(), bool) {
+	if limit < 1 {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	slots, exists := l.slots[key]
+	if !exists || slots.limit != limit {
+		// Checks holding a slot of a replaced limit release into the old
+		// channel, so changing the limit never blocks them
+		slots = targetSlots{limit: limit, ch: make(chan struct{}, limit)}
+		l.slots[key] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots.ch <- struct{}{}:
+		return func() { <-slots.ch }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// queuedCheck is a check waiting for a worker. It already holds a slot of its
+// target's limit, which release returns once the check is done.
+type queuedCheck struct {
+	cfg     CheckConfig
+	release func()
+}
+
+// dispatch queues cfg once a slot of its target's limit is free. Waiting here,
+// on the check's own ticker, rather than in a worker keeps a limited target
+// from occupying the pool while checks of other targets are queued. It returns
+// false if ctx is done first.
+func (hc *HealthChecker) dispatch(ctx context.Context, cfg CheckConfig) bool {
+	namespace, service := parseTargetName(cfg.targetName())
+	key := namespace + "/" + service
+	metadata, _ := hc.metadata.Get(key)
+	release, ok := hc.targetLimiter.acquire(ctx, key, metadata.MaxConcurrentChecks)
+	if !ok {
+		return false
+	}
+
+	select {
+	case hc.checkCh <- queuedCheck{cfg: cfg, release: release}:
+		return true
+	case <-ctx.Done():
+		release()
+		return false
+	}
+}
+
+func (hc *HealthChecker) runQueued(ctx context.Context, check queuedCheck) {
+	defer check.release()
+	hc.executeCheck(ctx, check.cfg)
+}
+
+func (l *targetLimiter) forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.slots, key)
+}
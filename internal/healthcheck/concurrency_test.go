@@ -0,0 +1,155 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_MaxConcurrentChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		checks      int
+		wantMaxBusy int32
+	}{
+		{name: "limit caps a target's checks", limit: 2, checks: 10, wantMaxBusy: 2},
+		{name: "limit above the number of checks", limit: 10, checks: 3, wantMaxBusy: 3},
+		{name: "single slot runs checks one at a time", limit: 1, checks: 4, wantMaxBusy: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var busy, maxBusy, served atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := busy.Add(1)
+				defer busy.Add(-1)
+				for {
+					current := maxBusy.Load()
+					if n <= current || maxBusy.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				served.Add(1)
+			}))
+			defer server.Close()
+
+			// Without a worker pool every check gets its own goroutine, so only
+			// the target limit holds them back
+			hc := NewHealthChecker(WithWorkers(0))
+			hc.SetTargetMetadata("default/api", TargetMetadata{MaxConcurrentChecks: tt.limit})
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				hc.Start(ctx)
+			}()
+
+			for i := range tt.checks {
+				go hc.dispatch(ctx, CheckConfig{
+					Name:    fmt.Sprintf("pod-%d", i),
+					URL:     fmt.Sprintf("%s/healthz?pod=%d", server.URL, i),
+					Timeout: time.Second,
+					target:  "default/api",
+				})
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) && served.Load() < int32(tt.checks) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := served.Load(); got != int32(tt.checks) {
+				t.Errorf("served %v checks, want %v", got, tt.checks)
+			}
+			if got := maxBusy.Load(); got > tt.wantMaxBusy {
+				t.Errorf("max concurrent checks = %v, want at most %v", got, tt.wantMaxBusy)
+			}
+
+			cancel()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Start() did not return after cancel")
+			}
+		})
+	}
+}
+
+func TestHealthChecker_MaxConcurrentChecksDoesNotStarvePool(t *testing.T) {
+	var limitedServed, servedBeforeOther atomic.Int32
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		limitedServed.Add(1)
+	}))
+	defer limited.Close()
+	otherServed := make(chan struct{}, 1)
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		servedBeforeOther.Store(limitedServed.Load())
+		otherServed <- struct{}{}
+	}))
+	defer other.Close()
+
+	hc := NewHealthChecker(WithWorkers(2))
+	hc.SetTargetMetadata("default/api", TargetMetadata{MaxConcurrentChecks: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = hc.Start(ctx) }()
+
+	const limitedChecks = 10
+	for i := range limitedChecks {
+		go hc.dispatch(ctx, CheckConfig{
+			Name:    fmt.Sprintf("pod-%d", i),
+			URL:     fmt.Sprintf("%s/healthz?pod=%d", limited.URL, i),
+			Timeout: time.Second,
+			target:  "default/api",
+		})
+	}
+	time.Sleep(20 * time.Millisecond)
+	hc.dispatch(ctx, CheckConfig{Name: "default/web", URL: other.URL, Timeout: time.Second})
+
+	select {
+	case <-otherServed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("check of an unlimited target was never served")
+	}
+	// The unlimited check only has to wait for a worker, not for the limited
+	// target's queue to drain
+	if got := servedBeforeOther.Load(); got > 2 {
+		t.Errorf("limited checks served before the unlimited one = %v, want at most 2", got)
+	}
+}
+
+func TestTargetLimiter_Acquire(t *testing.T) {
+	limiter := newTargetLimiter()
+	ctx := context.Background()
+
+	release, ok := limiter.acquire(ctx, "default/api", 1)
+	if !ok {
+		t.Fatalf("acquire() ok = false, want true")
+	}
+
+	waiting, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, ok := limiter.acquire(waiting, "default/api", 1); ok {
+		t.Errorf("acquire() beyond the limit ok = true, want false once ctx is done")
+	}
+	if _, ok := limiter.acquire(ctx, "default/web", 1); !ok {
+		t.Errorf("acquire() for another target ok = false, want true")
+	}
+
+	// Raising the limit must not wait on slots held under the old one
+	raised, ok := limiter.acquire(ctx, "default/api", 2)
+	if !ok {
+		t.Fatalf("acquire() after raising the limit ok = false, want true")
+	}
+	release()
+	raised()
+
+	if _, ok := limiter.acquire(ctx, "default/api", 0); !ok {
+		t.Errorf("acquire() without a limit ok = false, want true")
+	}
+}
@@ -0,0 +1,76 @@
+package healthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// defaultEventLogSize is how many state events are kept for GetEvents
+const defaultEventLogSize = 500
+
+// WithEventLogSize sets how many state events are kept. Values below one are
+// ignored and the default of 500 is kept.
+func WithEventLogSize(n int) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		if n < 1 {
+			return
+		}
+		hc.eventLog = newEventLog(n)
+	}
+}
+
+// eventLog is a fixed-size ring buffer of state events, oldest first
+type eventLog struct {
+	mu     sync.Mutex
+	events []types.StateEvent
+	start  int
+	size   int
+}
+
+func newEventLog(size int) *eventLog {
+	return &eventLog{events: make([]types.StateEvent, 0, size), size: size}
+}
+
+func (l *eventLog) record(event types.StateEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) < l.size {
+		l.events = append(l.events, event)
+		return
+	}
+	l.events[l.start] = event
+	l.start = (l.start + 1) % l.size
+}
+
+// since returns the events recorded after t in the order they happened
+func (l *eventLog) since(t time.Time) []types.StateEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make([]types.StateEvent, 0, len(l.events))
+	for i := range l.events {
+		event := l.events[(l.start+i)%len(l.events)]
+		if event.Timestamp.After(t) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (hc *HealthChecker) recordEvent(name string, eventType types.StateEventType) {
+	namespace, service := parseTargetName(name)
+	hc.eventLog.record(types.StateEvent{
+		Kind:      types.ResourceKindService,
+		Name:      service,
+		Namespace: namespace,
+		Type:      eventType,
+		Timestamp: time.Now(),
+	})
+}
+
+// GetEvents returns the targets added, updated and removed after since, oldest
+// first. A zero since returns every event still in the log.
+func (hc *HealthChecker) GetEvents(since time.Time) []types.StateEvent {
+	return hc.eventLog.since(since)
+}
@@ -0,0 +1,121 @@
+package healthcheck
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestEventLog(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := func(name string, offset time.Duration) types.StateEvent {
+		return types.StateEvent{Name: name, Type: types.StateEventAdd, Timestamp: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name   string
+		size   int
+		record []types.StateEvent
+		since  time.Time
+		want   []string
+	}{
+		{
+			name:   "empty",
+			size:   3,
+			record: nil,
+			want:   []string{},
+		},
+		{
+			name:   "in order",
+			size:   3,
+			record: []types.StateEvent{event("a", 0), event("b", time.Second)},
+			want:   []string{"a", "b"},
+		},
+		{
+			name: "oldest dropped once full",
+			size: 3,
+			record: []types.StateEvent{event("a", 0), event("b", time.Second), event("c", 2*time.Second),
+				event("d", 3*time.Second), event("e", 4*time.Second)},
+			want: []string{"c", "d", "e"},
+		},
+		{
+			name:   "since filter",
+			size:   5,
+			record: []types.StateEvent{event("a", 0), event("b", time.Minute), event("c", 2*time.Minute)},
+			since:  base.Add(30 * time.Second),
+			want:   []string{"b", "c"},
+		},
+		{
+			name:   "since excludes an event at exactly that time",
+			size:   5,
+			record: []types.StateEvent{event("a", 0), event("b", time.Minute)},
+			since:  base.Add(time.Minute),
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newEventLog(tt.size)
+			for _, e := range tt.record {
+				log.record(e)
+			}
+
+			got := []string{}
+			for _, e := range log.since(tt.since) {
+				got = append(got, e.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("since() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RecordsTargetEvents(t *testing.T) {
+	hc := NewHealthChecker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hc.listenForRegistrations(ctx)
+	go hc.listenForUnregistrations(ctx)
+
+	checks := []CheckConfig{{Name: "default/api", URL: "http://api/healthz", Interval: time.Hour, Timeout: time.Second}}
+	updated := []CheckConfig{{Name: "default/api", URL: "http://api/ready", Interval: time.Hour, Timeout: time.Second}}
+
+	waitForEvents := func(n int) []types.StateEvent {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if events := hc.GetEvents(time.Time{}); len(events) >= n {
+				return events
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("GetEvents() did not reach %d events", n)
+		return nil
+	}
+
+	hc.RegisterHealthTarget("default/api", checks)
+	waitForEvents(1)
+	hc.RegisterHealthTarget("default/api", updated)
+	waitForEvents(2)
+	hc.UnregisterHealthTarget("default/api")
+	events := waitForEvents(3)
+
+	want := []types.StateEventType{types.StateEventAdd, types.StateEventUpdate, types.StateEventDelete}
+	for i, event := range events {
+		if event.Type != want[i] {
+			t.Errorf("GetEvents()[%d].Type = %v, want %v", i, event.Type, want[i])
+		}
+		if event.Kind != types.ResourceKindService || event.Namespace != "default" || event.Name != "api" {
+			t.Errorf("GetEvents()[%d] = %+v, want Service default/api", i, event)
+		}
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Timestamp.Before(events[i-1].Timestamp) {
+			t.Errorf("GetEvents()[%d].Timestamp is before the event recorded ahead of it", i)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// defaultEnqueueTimeout bounds how long a caller waits for room on a full event channel
+const defaultEnqueueTimeout = 5 * time.Second
+
+var (
+	eventsBlocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "constellation_healthcheck_events_blocked_total",
+		Help: "Number of registration events that had to wait for room on a full channel.",
+	}, []string{"event"})
+
+	eventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "constellation_healthcheck_events_dropped_total",
+		Help: "Number of registration events dropped because the channel stayed full.",
+	}, []string{"event"})
+
+	eventsCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "constellation_healthcheck_events_coalesced_total",
+		Help: "Number of registration events merged into one already queued for the same target.",
+	}, []string{"event"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventsBlocked, eventsDropped, eventsCoalesced)
+}
+
+// WithEnqueueTimeout sets how long RegisterHealthTarget and UnregisterHealthTarget
+// wait for room when the event channel is full before dropping the event.
+// Zero drops immediately.
+func WithEnqueueTimeout(timeout time.Duration) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.enqueueTimeout = timeout
+	}
+}
+
+// enqueue sends v on ch, waiting at most the enqueue timeout or until the
+// checker stops. It reports whether the event was sent.
+func enqueue[T any](hc *HealthChecker, ch chan<- T, v T, event string) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+	}
+
+	eventsBlocked.WithLabelValues(event).Inc()
+	timer := time.NewTimer(hc.enqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case ch <- v:
+		return true
+	case <-timer.C:
+	case <-hc.stopped:
+	}
+
+	eventsDropped.WithLabelValues(event).Inc()
+	log.Log.WithName("healthcheck").Info("dropped event, channel is full", "event", event)
+	return false
+}
+
+// queueRegistration records target as the latest registration for its name.
+// It reports false when a registration for the same name is already waiting
+// on the channel, in which case that one picks up target instead.
+func (hc *HealthChecker) queueRegistration(target HealthTarget) bool {
+	hc.pendingMu.Lock()
+	defer hc.pendingMu.Unlock()
+	_, queued := hc.pendingRegistrations[target.Name]
+	hc.pendingRegistrations[target.Name] = target
+	return !queued
+}
+
+// takeRegistration removes and returns the latest queued registration for name
+func (hc *HealthChecker) takeRegistration(name string) (HealthTarget, bool) {
+	hc.pendingMu.Lock()
+	defer hc.pendingMu.Unlock()
+	target, ok := hc.pendingRegistrations[name]
+	delete(hc.pendingRegistrations, name)
+	return target, ok
+}
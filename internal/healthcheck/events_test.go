@@ -0,0 +1,100 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHealthChecker_EnqueueSaturated(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		send  func(hc *HealthChecker, i int)
+	}{
+		{
+			name:  "register",
+			event: "register",
+			send: func(hc *HealthChecker, i int) {
+				hc.RegisterHealthTarget(fmt.Sprintf("default/svc-%d", i), nil)
+			},
+		},
+		{
+			name:  "unregister",
+			event: "unregister",
+			send: func(hc *HealthChecker, i int) {
+				hc.UnregisterHealthTarget(fmt.Sprintf("default/svc-%d", i))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithEnqueueTimeout(10 * time.Millisecond))
+			dropped := testutil.ToFloat64(eventsDropped.WithLabelValues(tt.event))
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := range 105 {
+					tt.send(hc, i)
+				}
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s blocked on a saturated channel", tt.event)
+			}
+
+			if got := testutil.ToFloat64(eventsDropped.WithLabelValues(tt.event)) - dropped; got != 5 {
+				t.Errorf("dropped %s events = %v, want 5", tt.event, got)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_EnqueueStopped(t *testing.T) {
+	hc := NewHealthChecker(WithEnqueueTimeout(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := hc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 105 {
+			hc.UnregisterHealthTarget(fmt.Sprintf("default/svc-%d", i))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("UnregisterHealthTarget() blocked after the checker stopped")
+	}
+}
+
+func TestHealthChecker_RegisterCoalesces(t *testing.T) {
+	hc := NewHealthChecker()
+	first := []CheckConfig{{Name: "default/api", URL: "http://api/healthz"}}
+	second := []CheckConfig{{Name: "default/api", URL: "http://api/ready"}}
+
+	hc.RegisterHealthTarget("default/api", first)
+	hc.RegisterHealthTarget("default/api", second)
+
+	if got := len(hc.registerCh); got != 1 {
+		t.Errorf("RegisterHealthTarget() queued %v events, want 1", got)
+	}
+	target, ok := hc.takeRegistration(<-hc.registerCh)
+	if !ok {
+		t.Fatalf("takeRegistration() ok = false, want true")
+	}
+	if target.Checks[0].URL != second[0].URL {
+		t.Errorf("takeRegistration() url = %v, want %v", target.Checks[0].URL, second[0].URL)
+	}
+}
@@ -0,0 +1,41 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkGRPC calls the standard gRPC health service. The target is
+// "grpc://host:port" or "grpc://host:port/service" to check a named service.
+// A non-empty service replaces the one in the path. Like Kubernetes gRPC
+// probes it connects without TLS.
+func checkGRPC(ctx context.Context, target, service string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if service == "" {
+		service = strings.TrimPrefix(u.Path, "/")
+	}
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc health status %s", resp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_GRPCCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("api.v1.Users", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("api.v1.Orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	addr := listener.Addr().String()
+	tests := []struct {
+		name       string
+		url        string
+		service    string
+		wantStatus types.HealthStatus
+	}{
+		{name: "server health", url: "grpc://" + addr, wantStatus: "healthy"},
+		{name: "serving service", url: "grpc://" + addr + "/api.v1.Users", wantStatus: "healthy"},
+		{name: "not serving service", url: "grpc://" + addr + "/api.v1.Orders", wantStatus: "unhealthy"},
+		{name: "service from request body", url: "grpc://" + addr + "/api.v1.Orders", service: "api.v1.Users", wantStatus: "healthy"},
+		{name: "unknown service", url: "grpc://" + addr + "/api.v1.Missing", wantStatus: "unhealthy"},
+		{name: "nothing listening", url: "grpc://" + closedAddr, wantStatus: "unhealthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			cfg := CheckConfig{Name: "default/users", URL: tt.url, Timeout: 2 * time.Second, Protocol: "grpc", RequestBody: tt.service}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData(cfg.Name)
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, tt.wantStatus, info.History[0].Error)
+			}
+		})
+	}
+}
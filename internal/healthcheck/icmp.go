@@ -0,0 +1,115 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP   = 1
+	protocolICMPv6 = 58
+)
+
+var pingSeq atomic.Uint32
+
+// pingICMP sends one echo request to "icmp://host" and waits for its reply
+// until ctx is done.
+//
+// It first tries an unprivileged ICMP socket, which Linux allows for groups in
+// net.ipv4.ping_group_range. Otherwise it falls back to a raw socket, which
+// needs CAP_NET_RAW in the container's securityContext.
+func pingICMP(ctx context.Context, target string) error {
+	host := strings.TrimPrefix(target, "icmp://")
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", host)
+	}
+	ip := addrs[0].IP
+	v4 := ip.To4() != nil
+
+	conn, privileged, err := listenICMP(v4)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	var requestType, replyType icmp.Type = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	protocol := protocolICMPv6
+	if v4 {
+		requestType, replyType = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+		protocol = protocolICMP
+	}
+
+	// Unprivileged sockets have their ID rewritten by the kernel, so only the
+	// sequence number identifies the reply there
+	id := os.Getpid() & 0xffff
+	seq := int(pingSeq.Add(1) & 0xffff)
+	request, err := (&icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("constellation")},
+	}).Marshal(nil)
+	if err != nil {
+		return err
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		dst = &net.IPAddr{IP: ip}
+	}
+	if _, err := conn.WriteTo(request, dst); err != nil {
+		return err
+	}
+
+	buf := make([]byte, maxDatagramBytes)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return fmt.Errorf("no echo reply from %s: %w", host, err)
+		}
+		reply, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (privileged && echo.ID != id) {
+			continue
+		}
+		return nil
+	}
+}
+
+// listenICMP opens an ICMP socket and reports whether it is a raw one
+func listenICMP(v4 bool) (*icmp.PacketConn, bool, error) {
+	unprivileged, privileged, address := "udp6", "ip6:ipv6-icmp", "::"
+	if v4 {
+		unprivileged, privileged, address = "udp4", "ip4:icmp", "0.0.0.0"
+	}
+
+	conn, err := icmp.ListenPacket(unprivileged, address)
+	if err == nil {
+		return conn, false, nil
+	}
+	conn, rawErr := icmp.ListenPacket(privileged, address)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("icmp requires ping_group_range or CAP_NET_RAW: %w", errors.Join(err, rawErr))
+	}
+	return conn, true, nil
+}
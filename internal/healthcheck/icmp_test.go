@@ -0,0 +1,30 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_ICMPCheck(t *testing.T) {
+	// CI runners often allow neither unprivileged ping sockets nor raw sockets
+	conn, _, err := listenICMP(true)
+	if err != nil {
+		t.Skipf("icmp sockets unavailable: %v", err)
+	}
+	conn.Close()
+
+	hc := NewHealthChecker()
+	cfg := CheckConfig{Name: "default/loopback", URL: "icmp://127.0.0.1", Protocol: "icmp", Timeout: time.Second}
+	hc.executeCheck(context.Background(), cfg)
+
+	info, ok := hc.GetHealthData("default/loopback")
+	if !ok {
+		t.Fatalf("GetHealthData() ok = false, want true")
+	}
+	if info.Status != types.HealthStatusHealthy {
+		t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, types.HealthStatusHealthy, info.History[0].Error)
+	}
+}
@@ -0,0 +1,44 @@
+package healthcheck
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// WithJitter spreads checks out so targets registered together do not all hit
+// their endpoints at once. The first check is delayed by a random fraction of
+// the interval up to fraction, and every later tick is moved by up to
+// ±fraction/2 of the interval. fraction is clamped to [0, 1]; zero disables jitter.
+func WithJitter(fraction float64) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+func (hc *HealthChecker) initialDelay(interval time.Duration) time.Duration {
+	return time.Duration(rand.Float64() * hc.jitter * float64(interval))
+}
+
+func (hc *HealthChecker) jitteredInterval(interval time.Duration) time.Duration {
+	offset := (rand.Float64() - 0.5) * hc.jitter * float64(interval)
+	return interval + time.Duration(offset)
+}
+
+// runJitteredCheckTicker behaves like runCheckTicker but on randomized delays
+func (hc *HealthChecker) runJitteredCheckTicker(ctx context.Context, cfg CheckConfig) {
+	timer := time.NewTimer(hc.initialDelay(cfg.Interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !hc.dispatch(ctx, cfg) {
+				return
+			}
+			timer.Reset(hc.jitteredInterval(cfg.Interval))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
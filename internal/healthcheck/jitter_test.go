@@ -0,0 +1,88 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_JitterSpreadsInitialChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		jitter     float64
+		wantSpread bool
+	}{
+		{
+			name:       "without jitter checks fire together",
+			jitter:     0,
+			wantSpread: false,
+		},
+		{
+			name:       "with jitter checks are spread out",
+			jitter:     1,
+			wantSpread: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			const checks = 10
+			interval := 500 * time.Millisecond
+			hc := NewHealthChecker(WithJitter(tt.jitter))
+
+			start := time.Now()
+			for i := range checks {
+				go hc.runCheckTicker(ctx, CheckConfig{Name: fmt.Sprintf("default/svc-%d", i), Interval: interval})
+			}
+
+			var first, last time.Duration
+			for i := range checks {
+				select {
+				case <-hc.checkCh:
+				case <-time.After(2 * interval):
+					t.Fatalf("runCheckTicker() only %d of %d initial checks fired", i, checks)
+				}
+				elapsed := time.Since(start)
+				if i == 0 {
+					first = elapsed
+				}
+				last = elapsed
+			}
+
+			spread := last - first
+			if tt.wantSpread && spread < interval/10 {
+				t.Errorf("runCheckTicker() initial checks spread over %v, want at least %v", spread, interval/10)
+			}
+			if !tt.wantSpread && spread > interval/10 {
+				t.Errorf("runCheckTicker() initial checks spread over %v, want under %v", spread, interval/10)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_JitteredInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		fraction float64
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{name: "no jitter", fraction: 0, wantMin: 10 * time.Second, wantMax: 10 * time.Second},
+		{name: "half jitter", fraction: 0.5, wantMin: 7500 * time.Millisecond, wantMax: 12500 * time.Millisecond},
+		{name: "clamped above one", fraction: 5, wantMin: 5 * time.Second, wantMax: 15 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker(WithJitter(tt.fraction))
+			for range 100 {
+				got := hc.jitteredInterval(10 * time.Second)
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("jitteredInterval() = %v, want within [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
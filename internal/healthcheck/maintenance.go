@@ -0,0 +1,65 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// SetMaintenance puts a target under maintenance independently of its
+// discovery metadata, e.g. from an annotated HealthCheck. A target is under
+// maintenance while either this or TargetMetadata.Maintenance is set.
+func (hc *HealthChecker) SetMaintenance(name string, enabled bool) {
+	if hc.inMaintenance(name) == enabled {
+		return
+	}
+	if enabled {
+		hc.maintenance.Set(name, true)
+	}
+	if !enabled {
+		hc.maintenance.Delete(name)
+	}
+
+	info, previous := hc.refreshStatus(name)
+	if !hc.underMaintenance(name) {
+		hc.notifyTransition(info, previous)
+	}
+	hc.notifySubscribers()
+}
+
+// refreshStatus re-aggregates a stored target so a maintenance change shows
+// without waiting for its next check. It returns the stored entry and the
+// status it had before.
+func (hc *HealthChecker) refreshStatus(key string) (types.ServiceHealthInfo, types.HealthStatus) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	existing, exists := hc.healthData.Get(key)
+	if !exists {
+		return types.ServiceHealthInfo{}, ""
+	}
+	status := hc.serviceStatus(key, existing.Checks)
+	if status == existing.Status {
+		return *existing, status
+	}
+
+	info := *existing
+	info.Status = status
+	info.LastTransitionTime = time.Now()
+	hc.healthData.Set(key, &info)
+	if !hc.underMaintenance(key) {
+		hc.evaluateAlert(key, info)
+	}
+	return info, existing.Status
+}
+
+func (hc *HealthChecker) inMaintenance(name string) bool {
+	enabled, _ := hc.maintenance.Get(name)
+	return enabled
+}
+
+// underMaintenance reports whether either source puts a target under maintenance
+func (hc *HealthChecker) underMaintenance(key string) bool {
+	metadata, _ := hc.metadata.Get(key)
+	return metadata.Maintenance || hc.inMaintenance(key)
+}
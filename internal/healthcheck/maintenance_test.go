@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_Maintenance(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(hc *HealthChecker)
+		lift  func(hc *HealthChecker)
+	}{
+		{
+			name:  "service annotation via metadata",
+			apply: func(hc *HealthChecker) { hc.SetTargetMetadata("default/api", TargetMetadata{Maintenance: true}) },
+			lift:  func(hc *HealthChecker) { hc.SetTargetMetadata("default/api", TargetMetadata{}) },
+		},
+		{
+			name:  "health check annotation",
+			apply: func(hc *HealthChecker) { hc.SetMaintenance("default/api", true) },
+			lift:  func(hc *HealthChecker) { hc.SetMaintenance("default/api", false) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			hc := NewHealthChecker(WithNotifiers(notifier))
+			cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+			start := time.Now()
+
+			hc.recordCheckResult(cfg, start, 200, nil)
+			tt.apply(hc)
+			hc.recordCheckResult(cfg, start.Add(time.Minute), 500, nil)
+			hc.recordCheckResult(cfg, start.Add(2*time.Minute), 500, nil)
+
+			info, _ := hc.GetHealthData("default/api")
+			if info.Status != types.HealthStatusMaintenance {
+				t.Errorf("status in maintenance = %v, want %v", info.Status, types.HealthStatusMaintenance)
+			}
+			if got := info.History[len(info.History)-1].Status; got != types.HealthStatusUnhealthy {
+				t.Errorf("recorded history status = %v, want %v", got, types.HealthStatusUnhealthy)
+			}
+			if got := hc.GetHealthSummary().Maintenance; got != 1 {
+				t.Errorf("GetHealthSummary() maintenance = %v, want 1", got)
+			}
+
+			// Give any notification to arrive before checking none was sent
+			time.Sleep(50 * time.Millisecond)
+			if got := notifier.count(); got != 0 {
+				t.Fatalf("Notify() calls in maintenance = %v, want 0", got)
+			}
+
+			tt.lift(hc)
+			hc.recordCheckResult(cfg, start.Add(3*time.Minute), 500, nil)
+
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) && notifier.count() == 0 {
+				time.Sleep(5 * time.Millisecond)
+			}
+			want := Transition{From: types.HealthStatusMaintenance, To: types.HealthStatusUnhealthy}
+			notifier.mu.Lock()
+			defer notifier.mu.Unlock()
+			if len(notifier.transitions) != 1 || notifier.transitions[0] != want {
+				t.Errorf("Notify() transitions after maintenance = %v, want [%v]", notifier.transitions, want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_SetMaintenanceRefreshesStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		want    types.HealthStatus
+	}{
+		{name: "entering maintenance", enabled: true, want: types.HealthStatusMaintenance},
+		{name: "leaving maintenance", enabled: false, want: types.HealthStatusUnhealthy},
+	}
+
+	hc := NewHealthChecker()
+	hc.recordCheckResult(CheckConfig{Name: "default/api", URL: "http://api"}, time.Now(), 500, nil)
+	ch := hc.Subscribe()
+	defer hc.Unsubscribe(ch)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc.SetMaintenance("default/api", tt.enabled)
+
+			info, _ := hc.GetHealthData("default/api")
+			if info.Status != tt.want {
+				t.Errorf("SetMaintenance() status = %v, want %v", info.Status, tt.want)
+			}
+			select {
+			case data := <-ch:
+				if len(data) != 1 || data[0].Status != tt.want {
+					t.Errorf("SetMaintenance() published %v, want one service with status %v", data, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("SetMaintenance() published no update")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Transition is a change of a service's status between two consecutive checks
+type Transition struct {
+	From types.HealthStatus `json:"from"`
+	To   types.HealthStatus `json:"to"`
+}
+
+// Notifier is told about every status transition of a service. Notify is
+// called on its own goroutine, so a slow notifier does not delay checks.
+type Notifier interface {
+	Notify(ctx context.Context, info types.ServiceHealthInfo, transition Transition) error
+}
+
+// WithNotifiers adds notifiers called whenever a service changes status
+func WithNotifiers(notifiers ...Notifier) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.notifiers = append(hc.notifiers, notifiers...)
+	}
+}
+
+// notifyTransition calls every notifier for a status change of info. The
+// first result of a service has nothing to transition from and is skipped.
+func (hc *HealthChecker) notifyTransition(info types.ServiceHealthInfo, from types.HealthStatus) {
+	if from == "" || from == info.Status {
+		return
+	}
+
+	transition := Transition{From: from, To: info.Status}
+	for _, notifier := range hc.notifiers {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+			defer cancel()
+			if err := notifier.Notify(ctx, info, transition); err != nil {
+				log.Log.WithName("healthcheck").Error(err, "failed to notify health transition",
+					"service", info.Namespace+"/"+info.ServiceName, "from", transition.From, "to", transition.To)
+			}
+		}()
+	}
+}
+
+// Notification is the JSON payload posted by the webhook notifier
+type Notification struct {
+	Namespace   string             `json:"namespace"`
+	ServiceName string             `json:"service_name"`
+	From        types.HealthStatus `json:"from"`
+	To          types.HealthStatus `json:"to"`
+	URL         string             `json:"url"`
+	Error       string             `json:"error,omitempty"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+type webhookNotifier struct {
+	url    string
+	client HTTPClient
+}
+
+// NewWebhookNotifier posts a Notification to url for every transition
+func NewWebhookNotifier(url string, client HTTPClient) Notifier {
+	return &webhookNotifier{url: url, client: client}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, info types.ServiceHealthInfo, transition Transition) error {
+	notification := Notification{
+		Namespace:   info.Namespace,
+		ServiceName: info.ServiceName,
+		From:        transition.From,
+		To:          transition.To,
+		URL:         info.URL,
+		Timestamp:   info.LastCheck,
+	}
+	if len(info.History) > 0 {
+		notification.Error = info.History[len(info.History)-1].Error
+	}
+	return postJSON(ctx, n.client, n.url, notification)
+}
+
+type eventNotifier struct {
+	recorder record.EventRecorder
+}
+
+// NewEventNotifier records a Kubernetes Event on the Service for every
+// transition. Transitions away from healthy are Warning events.
+func NewEventNotifier(recorder record.EventRecorder) Notifier {
+	return &eventNotifier{recorder: recorder}
+}
+
+func (n *eventNotifier) Notify(_ context.Context, info types.ServiceHealthInfo, transition Transition) error {
+	service := &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Namespace:  info.Namespace,
+		Name:       info.ServiceName,
+	}
+
+	eventType := corev1.EventTypeNormal
+	if transition.To != types.HealthStatusHealthy {
+		eventType = corev1.EventTypeWarning
+	}
+	n.recorder.Eventf(service, eventType, "HealthTransition", "health changed from %s to %s", transition.From, transition.To)
+	return nil
+}
+
+// postJSON posts payload to url and fails on any non-2xx response
+func postJSON(ctx context.Context, client HTTPClient, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+type fakeNotifier struct {
+	mu          sync.Mutex
+	transitions []Transition
+	err         error
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, _ types.ServiceHealthInfo, transition Transition) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.transitions = append(f.transitions, transition)
+	return f.err
+}
+
+func (f *fakeNotifier) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.transitions)
+}
+
+func TestHealthChecker_Notifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []int
+		err     error
+		want    map[Transition]int
+	}{
+		{
+			name:    "first result is not a transition",
+			results: []int{200, 200, 200},
+			want:    map[Transition]int{},
+		},
+		{
+			name:    "each status change notifies once",
+			results: []int{200, 500, 500, 200, 500},
+			want: map[Transition]int{
+				{From: types.HealthStatusHealthy, To: types.HealthStatusUnhealthy}: 2,
+				{From: types.HealthStatusUnhealthy, To: types.HealthStatusHealthy}: 1,
+			},
+		},
+		{
+			name:    "notifier errors do not stop later notifications",
+			results: []int{500, 200, 500},
+			err:     errors.New("unavailable"),
+			want: map[Transition]int{
+				{From: types.HealthStatusUnhealthy, To: types.HealthStatusHealthy}: 1,
+				{From: types.HealthStatusHealthy, To: types.HealthStatusUnhealthy}: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &fakeNotifier{err: tt.err}
+			second := &fakeNotifier{err: tt.err}
+			hc := NewHealthChecker(WithNotifiers(first, second))
+
+			cfg := CheckConfig{Name: "default/api", URL: "http://api"}
+			for _, code := range tt.results {
+				hc.recordCheckResult(cfg, time.Now(), code, nil)
+			}
+
+			wantTotal := 0
+			for _, n := range tt.want {
+				wantTotal += n
+			}
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) && (first.count() < wantTotal || second.count() < wantTotal) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			// Give any unexpected extra notifications a chance to arrive
+			time.Sleep(50 * time.Millisecond)
+
+			for _, notifier := range []*fakeNotifier{first, second} {
+				got := map[Transition]int{}
+				notifier.mu.Lock()
+				for _, transition := range notifier.transitions {
+					got[transition]++
+				}
+				notifier.mu.Unlock()
+				if len(got) != len(tt.want) {
+					t.Errorf("Notify() transitions = %v, want %v", got, tt.want)
+					continue
+				}
+				for transition, n := range tt.want {
+					if got[transition] != n {
+						t.Errorf("Notify() transitions = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusBadGateway, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Notification
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("webhook received invalid payload: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer webhook.Close()
+
+			info := types.ServiceHealthInfo{
+				ServiceName: "api",
+				Namespace:   "default",
+				URL:         "http://api",
+				History:     []types.HealthCheckEntry{{Status: types.HealthStatusUnhealthy, Error: "timeout"}},
+			}
+			transition := Transition{From: types.HealthStatusHealthy, To: types.HealthStatusUnhealthy}
+			err := NewWebhookNotifier(webhook.URL, http.DefaultClient).Notify(context.Background(), info, transition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := Notification{
+				Namespace:   "default",
+				ServiceName: "api",
+				From:        types.HealthStatusHealthy,
+				To:          types.HealthStatusUnhealthy,
+				URL:         "http://api",
+				Error:       "timeout",
+			}
+			if got != want {
+				t.Errorf("webhook payload = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEventNotifier_Notify(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition Transition
+		want       string
+	}{
+		{
+			name:       "becoming unhealthy is a warning",
+			transition: Transition{From: types.HealthStatusHealthy, To: types.HealthStatusUnhealthy},
+			want:       "Warning HealthTransition health changed from healthy to unhealthy",
+		},
+		{
+			name:       "recovery is normal",
+			transition: Transition{From: types.HealthStatusUnhealthy, To: types.HealthStatusHealthy},
+			want:       "Normal HealthTransition health changed from unhealthy to healthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			info := types.ServiceHealthInfo{ServiceName: "api", Namespace: "default"}
+			if err := NewEventNotifier(recorder).Notify(context.Background(), info, tt.transition); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			if got := <-recorder.Events; got != tt.want {
+				t.Errorf("recorded event = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
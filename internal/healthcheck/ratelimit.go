@@ -0,0 +1,71 @@
+package healthcheck
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultManualCheckLimit is how many manual checks a target accepts per minute
+const defaultManualCheckLimit = 6
+
+// ErrRateLimited is returned when a target was checked manually too often
+var ErrRateLimited = errors.New("manual check rate limit exceeded")
+
+// WithManualCheckLimit caps RunCheckNow at perMinute calls per target per
+// minute so the trigger endpoint cannot be used to flood a service. Scheduled
+// checks are not limited. Zero or less removes the limit.
+func WithManualCheckLimit(perMinute int) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		if perMinute <= 0 {
+			hc.manualLimiter = nil
+			return
+		}
+		hc.manualLimiter = newManualCheckLimiter(perMinute)
+	}
+}
+
+// manualCheckLimiter keeps a token bucket per target that refills perMinute
+// tokens every minute
+type manualCheckLimiter struct {
+	limit    rate.Limit
+	burst    int
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newManualCheckLimiter(perMinute int) *manualCheckLimiter {
+	return &manualCheckLimiter{
+		limit:    rate.Every(time.Minute / time.Duration(perMinute)),
+		burst:    perMinute,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// allow reports whether name may be checked manually at now
+func (l *manualCheckLimiter) allow(name string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, exists := l.limiters[name]
+	if !exists {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[name] = limiter
+	}
+	return limiter.AllowN(now, 1)
+}
+
+func (l *manualCheckLimiter) forget(name string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.limiters, name)
+}
@@ -0,0 +1,222 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/healthcheck/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthChecker_RunCheckNowRateLimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []HealthCheckerOpt
+		wantErrs []error
+	}{
+		{
+			name:     "second rapid call is limited",
+			opts:     []HealthCheckerOpt{WithManualCheckLimit(1)},
+			wantErrs: []error{nil, ErrRateLimited},
+		},
+		{
+			name:     "burst up to the limit",
+			opts:     []HealthCheckerOpt{WithManualCheckLimit(2)},
+			wantErrs: []error{nil, nil, ErrRateLimited},
+		},
+		{
+			name:     "unlimited",
+			opts:     []HealthCheckerOpt{WithManualCheckLimit(0)},
+			wantErrs: []error{nil, nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockHTTPClient(ctrl)
+			client.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil).AnyTimes()
+
+			hc := NewHealthChecker(append([]HealthCheckerOpt{WithHTTPClient(client)}, tt.opts...)...)
+			hc.healthTargets.Set("default/api", HealthTarget{
+				Name:   "default/api",
+				Checks: []CheckConfig{{Name: "default/api", URL: "http://api", Interval: time.Hour, Timeout: time.Second}},
+			})
+
+			for i, wantErr := range tt.wantErrs {
+				if _, err := hc.RunCheckNow(context.Background(), "default/api"); !errors.Is(err, wantErr) {
+					t.Errorf("RunCheckNow() call %d error = %v, want %v", i+1, err, wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestManualCheckLimiter_Allow(t *testing.T) {
+	limiter := newManualCheckLimiter(1)
+	now := time.Now()
+
+	if !limiter.allow("default/api", now) {
+		t.Fatalf("allow() first call = false, want true")
+	}
+	if limiter.allow("default/api", now.Add(time.Second)) {
+		t.Errorf("allow() rapid second call = true, want false")
+	}
+	if !limiter.allow("default/web", now.Add(time.Second)) {
+		t.Errorf("allow() other target = false, want true")
+	}
+	if !limiter.allow("default/api", now.Add(time.Minute)) {
+		t.Errorf("allow() after the window = false, want true")
+	}
+}
+
+func TestHealthChecker_RunNamespaceChecksNow(t *testing.T) {
+	tests := []struct {
+		name            string
+		namespace       string
+		opts            []HealthCheckerOpt
+		checkedBefore   []string
+		cancelled       bool
+		wantErr         error
+		wantServices    []string
+		wantRateLimited []string
+	}{
+		{
+			name:         "only the requested namespace runs",
+			namespace:    "default",
+			wantServices: []string{"api", "web"},
+		},
+		{
+			name:      "unknown namespace",
+			namespace: "missing",
+			wantErr:   ErrTargetNotFound,
+		},
+		{
+			name:            "rate limited targets are reported",
+			namespace:       "default",
+			opts:            []HealthCheckerOpt{WithManualCheckLimit(1)},
+			checkedBefore:   []string{"default/api"},
+			wantServices:    []string{"web"},
+			wantRateLimited: []string{"api"},
+		},
+		{
+			name:          "every target rate limited",
+			namespace:     "default",
+			opts:          []HealthCheckerOpt{WithManualCheckLimit(1)},
+			checkedBefore: []string{"default/api", "default/web"},
+			wantErr:       ErrRateLimited,
+		},
+		{
+			name:      "cancelled request",
+			namespace: "default",
+			cancelled: true,
+			wantErr:   context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requested []string
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockHTTPClient(ctrl)
+			client.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				requested = append(requested, req.URL.Host)
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}).AnyTimes()
+
+			hc := NewHealthChecker(append([]HealthCheckerOpt{WithHTTPClient(client)}, tt.opts...)...)
+			for _, name := range []string{"default/api", "default/web", "other/db"} {
+				_, service := parseTargetName(name)
+				hc.healthTargets.Set(name, HealthTarget{
+					Name:   name,
+					Checks: []CheckConfig{{Name: name, URL: "http://" + service, Interval: time.Hour, Timeout: time.Second}},
+				})
+			}
+			for _, name := range tt.checkedBefore {
+				if _, err := hc.RunCheckNow(context.Background(), name); err != nil {
+					t.Fatalf("RunCheckNow() error = %v", err)
+				}
+			}
+			mu.Lock()
+			requested = nil
+			mu.Unlock()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			results, err := hc.RunNamespaceChecksNow(ctx, tt.namespace)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunNamespaceChecksNow() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			services := make([]string, 0, len(results.Services))
+			for service, entries := range results.Services {
+				services = append(services, service)
+				if len(entries) != 1 {
+					t.Errorf("RunNamespaceChecksNow() %s entries = %v, want 1", service, len(entries))
+				}
+			}
+			slices.Sort(services)
+			if !slices.Equal(services, tt.wantServices) {
+				t.Errorf("RunNamespaceChecksNow() services = %v, want %v", services, tt.wantServices)
+			}
+			if !slices.Equal(results.RateLimited, tt.wantRateLimited) {
+				t.Errorf("RunNamespaceChecksNow() rate limited = %v, want %v", results.RateLimited, tt.wantRateLimited)
+			}
+			slices.Sort(requested)
+			if !slices.Equal(requested, tt.wantServices) {
+				t.Errorf("RunNamespaceChecksNow() requested = %v, want %v", requested, tt.wantServices)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_RunNamespaceChecksNowBounded(t *testing.T) {
+	var busy, maxBusy atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := busy.Add(1)
+		defer busy.Add(-1)
+		for {
+			current := maxBusy.Load()
+			if n <= current || maxBusy.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker(WithWorkers(2), WithManualCheckLimit(0))
+	for i := range 8 {
+		name := fmt.Sprintf("default/svc-%d", i)
+		hc.healthTargets.Set(name, HealthTarget{
+			Name:   name,
+			Checks: []CheckConfig{{Name: name, URL: server.URL, Interval: time.Hour, Timeout: time.Second}},
+		})
+	}
+
+	results, err := hc.RunNamespaceChecksNow(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("RunNamespaceChecksNow() error = %v", err)
+	}
+	if len(results.Services) != 8 {
+		t.Errorf("RunNamespaceChecksNow() services = %v, want 8", len(results.Services))
+	}
+	if got := maxBusy.Load(); got > 2 {
+		t.Errorf("RunNamespaceChecksNow() concurrent checks = %v, want at most 2", got)
+	}
+}
@@ -0,0 +1,208 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+const (
+	defaultRemoteWriteBatchSize     = 500
+	defaultRemoteWriteFlushInterval = 10 * time.Second
+	// defaultRemoteWriteBuffer bounds the samples held while the endpoint is
+	// slow. Samples beyond it are dropped rather than delaying checks.
+	defaultRemoteWriteBuffer  = 10000
+	defaultRemoteWriteTimeout = 10 * time.Second
+)
+
+var remoteWriteSamples = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "constellation_remote_write_samples_total",
+	Help: "Number of check samples handled by the remote-write exporter by result.",
+}, []string{"result"})
+
+func init() {
+	metrics.Registry.MustRegister(remoteWriteSamples)
+}
+
+// Exporter receives every check result, e.g. to retain history beyond the
+// in-memory window. check names the check within target, as in
+// ServiceHealthInfo.Checks. Export is called on the checking goroutine and
+// must not block.
+type Exporter interface {
+	Export(target, check string, entry types.HealthCheckEntry)
+}
+
+// WithExporters adds exporters that receive every check result
+func WithExporters(exporters ...Exporter) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.exporters = append(hc.exporters, exporters...)
+	}
+}
+
+func (hc *HealthChecker) export(target, check string, entry types.HealthCheckEntry) {
+	for _, exporter := range hc.exporters {
+		exporter.Export(target, check, entry)
+	}
+}
+
+// remoteSample is one check result waiting to be written
+type remoteSample struct {
+	target string
+	check  string
+	entry  types.HealthCheckEntry
+}
+
+// RemoteWriteExporter pushes check results to a Prometheus remote-write
+// endpoint in batches. Each result becomes a constellation_check_up sample
+// (1 when healthy) and a constellation_check_latency_seconds sample, labeled
+// with the check so the checks of one service are separate series.
+type RemoteWriteExporter struct {
+	url           string
+	client        HTTPClient
+	samples       chan remoteSample
+	batchSize     int
+	flushInterval time.Duration
+}
+
+// NewRemoteWriteExporter writes to url. Call Run to start sending.
+func NewRemoteWriteExporter(url string, client HTTPClient) *RemoteWriteExporter {
+	return &RemoteWriteExporter{
+		url:           url,
+		client:        client,
+		samples:       make(chan remoteSample, defaultRemoteWriteBuffer),
+		batchSize:     defaultRemoteWriteBatchSize,
+		flushInterval: defaultRemoteWriteFlushInterval,
+	}
+}
+
+// Export queues the result for the next batch, dropping it when the buffer is full
+func (e *RemoteWriteExporter) Export(target, check string, entry types.HealthCheckEntry) {
+	select {
+	case e.samples <- remoteSample{target: target, check: check, entry: entry}:
+	default:
+		remoteWriteSamples.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Run sends a batch whenever it fills or the flush interval passes, until ctx
+// is done. A batch the endpoint rejects is dropped, not retried.
+func (e *RemoteWriteExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]remoteSample, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		result := "sent"
+		if err := e.write(ctx, batch); err != nil {
+			log.Log.WithName("healthcheck").Error(err, "failed to remote-write check samples", "samples", len(batch))
+			result = "failed"
+		}
+		remoteWriteSamples.WithLabelValues(result).Add(float64(len(batch)))
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case sample := <-e.samples:
+			batch = append(batch, sample)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *RemoteWriteExporter) write(ctx context.Context, batch []remoteSample) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultRemoteWriteTimeout)
+	defer cancel()
+
+	body := snappy.Encode(nil, encodeWriteRequest(batch))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest by hand, which avoids
+// depending on the Prometheus server module for four small messages:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(batch []remoteSample) []byte {
+	var request []byte
+	for _, sample := range batch {
+		namespace, service := parseTargetName(sample.target)
+		labels := [][2]string{{"check", sample.check}, {"namespace", namespace}, {"service", service}}
+		timestamp := sample.entry.Timestamp.UnixMilli()
+
+		up := 0.0
+		if sample.entry.Status == types.HealthStatusHealthy {
+			up = 1
+		}
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, encodeTimeSeries("constellation_check_up", labels, up, timestamp))
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, encodeTimeSeries("constellation_check_latency_seconds",
+			labels, sample.entry.Latency.Seconds(), timestamp))
+	}
+	return request
+}
+
+// encodeTimeSeries encodes one sample of the series name. Remote-write
+// receivers require labels sorted by name, so labels must already be sorted.
+func encodeTimeSeries(name string, labels [][2]string, value float64, timestamp int64) []byte {
+	var series []byte
+	for _, label := range append([][2]string{{"__name__", name}}, labels...) {
+		var encoded []byte
+		encoded = protowire.AppendTag(encoded, 1, protowire.BytesType)
+		encoded = protowire.AppendString(encoded, label[0])
+		encoded = protowire.AppendTag(encoded, 2, protowire.BytesType)
+		encoded = protowire.AppendString(encoded, label[1])
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, encoded)
+	}
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(timestamp))
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	return protowire.AppendBytes(series, sample)
+}
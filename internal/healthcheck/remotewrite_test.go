@@ -0,0 +1,211 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// writtenSample is a decoded remote-write series with a single sample
+type writtenSample struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+// decodeWriteRequest is a test-only decoder for the fields encodeWriteRequest writes
+func decodeWriteRequest(t *testing.T, body []byte) []writtenSample {
+	t.Helper()
+	var samples []writtenSample
+	forEachField(t, body, func(_ protowire.Number, series []byte) {
+		sample := writtenSample{labels: make(map[string]string)}
+		forEachField(t, series, func(num protowire.Number, field []byte) {
+			if num == 1 {
+				var name, value string
+				forEachField(t, field, func(num protowire.Number, part []byte) {
+					if num == 1 {
+						name = string(part)
+						return
+					}
+					value = string(part)
+				})
+				sample.labels[name] = value
+				return
+			}
+			for len(field) > 0 {
+				num, typ, n := protowire.ConsumeTag(field)
+				field = field[n:]
+				if num == 1 && typ == protowire.Fixed64Type {
+					bits, n := protowire.ConsumeFixed64(field)
+					sample.value = math.Float64frombits(bits)
+					field = field[n:]
+					continue
+				}
+				v, n := protowire.ConsumeVarint(field)
+				sample.timestamp = int64(v)
+				field = field[n:]
+			}
+		})
+		samples = append(samples, sample)
+	})
+	return samples
+}
+
+func forEachField(t *testing.T, b []byte, fn func(protowire.Number, []byte)) {
+	t.Helper()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || typ != protowire.BytesType {
+			t.Fatalf("unexpected field %d of type %d", num, typ)
+		}
+		b = b[n:]
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatalf("malformed field %d", num)
+		}
+		fn(num, value)
+		b = b[n:]
+	}
+}
+
+func TestRemoteWriteExporter_SendsSamples(t *testing.T) {
+	received := make(chan []writtenSample, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("remote write headers = %v, want snappy protobuf", r.Header)
+		}
+		compressed, _ := io.ReadAll(r.Body)
+		body, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Errorf("snappy.Decode() error = %v", err)
+		}
+		received <- decodeWriteRequest(t, body)
+	}))
+	defer server.Close()
+
+	exporter := NewRemoteWriteExporter(server.URL, server.Client())
+	exporter.batchSize = 2
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		exporter.Run(ctx)
+	}()
+	// The write is still in flight when the receiver hands over the samples,
+	// so wait for it to finish before other tests read the counters
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	timestamp := time.UnixMilli(1700000000000)
+	hc := NewHealthChecker(WithExporters(exporter))
+	hc.recordResult(CheckConfig{Name: "default/api", URL: "http://api"}, checkResult{start: timestamp, latency: 250 * time.Millisecond, statusCode: 200})
+	hc.recordResult(CheckConfig{Name: "payments/ledger", URL: "http://ledger"}, checkResult{start: timestamp, statusCode: 503})
+
+	var got []writtenSample
+	select {
+	case got = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("remote write receiver got no request")
+	}
+
+	want := []writtenSample{
+		{labels: map[string]string{"__name__": "constellation_check_up", "check": "http://api", "namespace": "default", "service": "api"}, value: 1, timestamp: timestamp.UnixMilli()},
+		{labels: map[string]string{"__name__": "constellation_check_latency_seconds", "check": "http://api", "namespace": "default", "service": "api"}, value: 0.25, timestamp: timestamp.UnixMilli()},
+		{labels: map[string]string{"__name__": "constellation_check_up", "check": "http://ledger", "namespace": "payments", "service": "ledger"}, value: 0, timestamp: timestamp.UnixMilli()},
+		{labels: map[string]string{"__name__": "constellation_check_latency_seconds", "check": "http://ledger", "namespace": "payments", "service": "ledger"}, value: 0, timestamp: timestamp.UnixMilli()},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("remote write samples = %+v, want %+v", got, want)
+	}
+}
+
+func TestRemoteWriteExporter_DropsWhenFull(t *testing.T) {
+	exporter := NewRemoteWriteExporter("http://unused", http.DefaultClient)
+	exporter.samples = make(chan remoteSample, 1)
+
+	before := testutil.ToFloat64(remoteWriteSamples.WithLabelValues("dropped"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 3 {
+			exporter.Export("default/api", "http://api", types.HealthCheckEntry{Status: types.HealthStatusHealthy})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Export() blocked on a full buffer")
+	}
+	if got := testutil.ToFloat64(remoteWriteSamples.WithLabelValues("dropped")) - before; got != 2 {
+		t.Errorf("dropped samples = %v, want 2", got)
+	}
+}
+
+func TestRemoteWriteExporter_UnreachableEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	exporter := NewRemoteWriteExporter(url, http.DefaultClient)
+	exporter.batchSize = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go exporter.Run(ctx)
+
+	before := testutil.ToFloat64(remoteWriteSamples.WithLabelValues("failed"))
+	exporter.Export("default/api", "http://api", types.HealthCheckEntry{Status: types.HealthStatusHealthy})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && testutil.ToFloat64(remoteWriteSamples.WithLabelValues("failed")) == before {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := testutil.ToFloat64(remoteWriteSamples.WithLabelValues("failed")) - before; got != 1 {
+		t.Errorf("failed samples = %v, want 1", got)
+	}
+}
+
+func TestEncodeWriteRequest_SeparatesChecksOfOneService(t *testing.T) {
+	timestamp := time.UnixMilli(1700000000000)
+	hc := NewHealthChecker()
+	var batch []remoteSample
+	hc.exporters = []Exporter{exporterFunc(func(target, check string, entry types.HealthCheckEntry) {
+		batch = append(batch, remoteSample{target: target, check: check, entry: entry})
+	})}
+	hc.recordResult(CheckConfig{Name: "default/api", URL: "http://api-0:8080/healthz"}, checkResult{start: timestamp, statusCode: 200})
+	hc.recordResult(CheckConfig{Name: "default/api", URL: "http://api-1:8080/healthz"}, checkResult{start: timestamp, statusCode: 503})
+
+	series := make(map[string]float64)
+	for _, sample := range decodeWriteRequest(t, encodeWriteRequest(batch)) {
+		if sample.labels["__name__"] != "constellation_check_up" {
+			continue
+		}
+		if sample.labels["service"] != "api" {
+			t.Errorf("sample service = %q, want api", sample.labels["service"])
+		}
+		series[sample.labels["check"]] = sample.value
+	}
+	want := map[string]float64{"http://api-0:8080/healthz": 1, "http://api-1:8080/healthz": 0}
+	if !reflect.DeepEqual(series, want) {
+		t.Errorf("constellation_check_up series by check = %v, want %v", series, want)
+	}
+}
+
+type exporterFunc func(target, check string, entry types.HealthCheckEntry)
+
+func (f exporterFunc) Export(target, check string, entry types.HealthCheckEntry) {
+	f(target, check, entry)
+}
@@ -0,0 +1,73 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+
+// WithWorkloadReader sets the client replicas checks read workload status from
+func WithWorkloadReader(reader client.Reader) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.workloadReader = reader
+	}
+}
+
+// ParseWorkloadTarget splits a replicas check target of the form
+// "replicas://<deployment|statefulset>/<namespace>/<name>"
+func ParseWorkloadTarget(target string) (kind, namespace, name string, err error) {
+	ref, found := strings.CutPrefix(target, "replicas://")
+	parts := strings.Split(ref, "/")
+	if !found || len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("replicas url must be replicas://<deployment|statefulset>/<namespace>/<name>")
+	}
+	kind = strings.ToLower(parts[0])
+	if kind != "deployment" && kind != "statefulset" {
+		return "", "", "", fmt.Errorf("unsupported workload kind %q, want deployment or statefulset", parts[0])
+	}
+	return kind, parts[1], parts[2], nil
+}
+
+// checkReplicas fails while the workload has fewer ready replicas than it wants
+func (hc *HealthChecker) checkReplicas(ctx context.Context, target string) error {
+	if hc.workloadReader == nil {
+		return fmt.Errorf("replicas checks need a workload reader")
+	}
+	kind, namespace, name, err := ParseWorkloadTarget(target)
+	if err != nil {
+		return err
+	}
+
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	var desired *int32
+	var ready int32
+	switch kind {
+	case "deployment":
+		var deployment appsv1.Deployment
+		if err := hc.workloadReader.Get(ctx, key, &deployment); err != nil {
+			return err
+		}
+		desired, ready = deployment.Spec.Replicas, deployment.Status.ReadyReplicas
+	case "statefulset":
+		var statefulSet appsv1.StatefulSet
+		if err := hc.workloadReader.Get(ctx, key, &statefulSet); err != nil {
+			return err
+		}
+		desired, ready = statefulSet.Spec.Replicas, statefulSet.Status.ReadyReplicas
+	}
+
+	// Both kinds default an unset replica count to one
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+	if ready < want {
+		return fmt.Errorf("%d of %d replicas ready", ready, want)
+	}
+	return nil
+}
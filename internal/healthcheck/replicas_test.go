@@ -0,0 +1,116 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_ReplicasCheck(t *testing.T) {
+	deployment := func(name string, desired *int32, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: desired},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		objects    []client.Object
+		url        string
+		wantStatus types.HealthStatus
+	}{
+		{
+			name:       "fully ready deployment",
+			objects:    []client.Object{deployment("api", ptr.To[int32](3), 3)},
+			url:        "replicas://deployment/default/api",
+			wantStatus: types.HealthStatusHealthy,
+		},
+		{
+			name:       "degraded deployment",
+			objects:    []client.Object{deployment("api", ptr.To[int32](3), 2)},
+			url:        "replicas://deployment/default/api",
+			wantStatus: types.HealthStatusUnhealthy,
+		},
+		{
+			name:       "unset replicas wants one",
+			objects:    []client.Object{deployment("api", nil, 0)},
+			url:        "replicas://deployment/default/api",
+			wantStatus: types.HealthStatusUnhealthy,
+		},
+		{
+			name:       "scaled to zero",
+			objects:    []client.Object{deployment("api", ptr.To[int32](0), 0)},
+			url:        "replicas://deployment/default/api",
+			wantStatus: types.HealthStatusHealthy,
+		},
+		{
+			name: "ready statefulset",
+			objects: []client.Object{&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			}},
+			url:        "replicas://statefulset/default/db",
+			wantStatus: types.HealthStatusHealthy,
+		},
+		{
+			name:       "missing workload",
+			url:        "replicas://deployment/default/api",
+			wantStatus: types.HealthStatusUnhealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := fake.NewClientBuilder().WithObjects(tt.objects...).Build()
+			hc := NewHealthChecker(WithWorkloadReader(reader))
+			cfg := CheckConfig{Name: "default/api", URL: tt.url, Protocol: "replicas", Timeout: time.Second}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData("default/api")
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, tt.wantStatus, info.History[0].Error)
+			}
+		})
+	}
+}
+
+func TestParseWorkloadTarget(t *testing.T) {
+	tests := []struct {
+		target        string
+		wantKind      string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{target: "replicas://deployment/default/api", wantKind: "deployment", wantNamespace: "default", wantName: "api"},
+		{target: "replicas://StatefulSet/data/db", wantKind: "statefulset", wantNamespace: "data", wantName: "db"},
+		{target: "replicas://daemonset/default/agent", wantErr: true},
+		{target: "replicas://deployment/api", wantErr: true},
+		{target: "deployment/default/api", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			kind, namespace, name, err := ParseWorkloadTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWorkloadTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if kind != tt.wantKind || namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("ParseWorkloadTarget() = %v, %v, %v, want %v, %v, %v",
+					kind, namespace, name, tt.wantKind, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxRequestBodyBytes bounds the request body a check may send
+const MaxRequestBodyBytes = 64 << 10
+
+// method is the HTTP method of the check, GET unless configured
+func (cfg CheckConfig) method() string {
+	if cfg.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(cfg.Method)
+}
+
+// MethodAllowsBody reports whether a check using method sends its RequestBody
+func MethodAllowsBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// newCheckRequest builds the request of an http check. The body is only sent
+// for methods that permit one.
+func newCheckRequest(ctx context.Context, cfg CheckConfig) (*http.Request, error) {
+	method := cfg.method()
+	if !MethodAllowsBody(method) || cfg.RequestBody == "" {
+		return http.NewRequestWithContext(ctx, method, cfg.URL, nil)
+	}
+	if len(cfg.RequestBody) > MaxRequestBodyBytes {
+		return nil, fmt.Errorf("request body is %d bytes, limit is %d", len(cfg.RequestBody), MaxRequestBodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL, strings.NewReader(cfg.RequestBody))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ContentType != "" {
+		req.Header.Set("Content-Type", cfg.ContentType)
+	}
+	return req, nil
+}
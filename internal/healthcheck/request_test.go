@@ -0,0 +1,95 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_RequestBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		body            string
+		contentType     string
+		wantMethod      string
+		wantBody        string
+		wantContentType string
+		wantStatus      types.HealthStatus
+	}{
+		{
+			name:       "default get sends no body",
+			wantMethod: http.MethodGet,
+			wantStatus: "healthy",
+		},
+		{
+			name:            "post sends body and content type",
+			method:          http.MethodPost,
+			body:            `{"probe":true}`,
+			contentType:     "application/json",
+			wantMethod:      http.MethodPost,
+			wantBody:        `{"probe":true}`,
+			wantContentType: "application/json",
+			wantStatus:      "healthy",
+		},
+		{
+			name:       "head ignores body",
+			method:     http.MethodHead,
+			body:       "ignored",
+			wantMethod: http.MethodHead,
+			wantStatus: "healthy",
+		},
+		{
+			name:       "oversized body is not sent",
+			method:     http.MethodPut,
+			body:       strings.Repeat("a", MaxRequestBodyBytes+1),
+			wantStatus: "unhealthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotBody, gotContentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotMethod, gotBody, gotContentType = r.Method, string(body), r.Header.Get("Content-Type")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:        "default/api",
+				URL:         server.URL,
+				Timeout:     time.Second,
+				Protocol:    "http",
+				Method:      tt.method,
+				RequestBody: tt.body,
+				ContentType: tt.contentType,
+			}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData(cfg.Name)
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v", info.Status, tt.wantStatus)
+			}
+			if gotMethod != tt.wantMethod {
+				t.Errorf("executeCheck() method = %q, want %q", gotMethod, tt.wantMethod)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("executeCheck() body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if gotContentType != tt.wantContentType {
+				t.Errorf("executeCheck() content type = %q, want %q", gotContentType, tt.wantContentType)
+			}
+		})
+	}
+}
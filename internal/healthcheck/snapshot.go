@@ -0,0 +1,54 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// SaveSnapshot writes the current health data as JSON
+func (hc *HealthChecker) SaveSnapshot(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(hc.GetAllHealthData()); err != nil {
+		return fmt.Errorf("failed to encode health snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads health data previously written by SaveSnapshot. Restored
+// entries are held back until their target is registered again, so services
+// that disappeared while the process was down are never shown.
+func (hc *HealthChecker) LoadSnapshot(r io.Reader) error {
+	var data []*types.ServiceHealthInfo
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("failed to decode health snapshot: %w", err)
+	}
+
+	for _, info := range data {
+		if info == nil {
+			continue
+		}
+		hc.restored.Set(info.Namespace+"/"+info.ServiceName, info)
+	}
+	return nil
+}
+
+// restoreHealthData seeds health data for a newly registered target from a loaded snapshot
+func (hc *HealthChecker) restoreHealthData(name string) {
+	namespace, service := parseTargetName(name)
+	key := namespace + "/" + service
+
+	info, exists := hc.restored.Get(key)
+	if !exists {
+		return
+	}
+	hc.restored.Delete(key)
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if _, exists := hc.healthData.Get(key); exists {
+		return
+	}
+	hc.healthData.Set(key, info)
+}
@@ -0,0 +1,123 @@
+package healthcheck
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_SnapshotRoundTrip(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		checks  []CheckConfig
+		errs    []error
+		restore []string
+		want    int
+	}{
+		{
+			name: "restores registered targets",
+			checks: []CheckConfig{
+				{Name: "default/api", URL: "http://api.default.svc.cluster.local:8080/health"},
+				{Name: "web/frontend", URL: "http://frontend.web.svc.cluster.local/healthz"},
+			},
+			errs:    []error{nil, errors.New("connection refused")},
+			restore: []string{"default/api", "web/frontend"},
+			want:    2,
+		},
+		{
+			name: "restores only targets registered again",
+			checks: []CheckConfig{
+				{Name: "default/api", URL: "http://api.default.svc.cluster.local:8080/health"},
+				{Name: "default/removed", URL: "http://removed.default.svc.cluster.local/health"},
+			},
+			errs:    []error{nil, nil},
+			restore: []string{"default/api"},
+			want:    1,
+		},
+		{
+			name:    "empty snapshot",
+			restore: []string{"default/api"},
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewHealthChecker()
+			for i, cfg := range tt.checks {
+				source.recordCheckResult(cfg, startTime, 200, tt.errs[i])
+			}
+
+			var buf bytes.Buffer
+			if err := source.SaveSnapshot(&buf); err != nil {
+				t.Fatalf("SaveSnapshot() error = %v", err)
+			}
+
+			restored := NewHealthChecker()
+			if err := restored.LoadSnapshot(&buf); err != nil {
+				t.Fatalf("LoadSnapshot() error = %v", err)
+			}
+			if got := len(restored.GetAllHealthData()); got != 0 {
+				t.Errorf("LoadSnapshot() exposed %v entries before registration, want 0", got)
+			}
+
+			for _, name := range tt.restore {
+				restored.restoreHealthData(name)
+			}
+
+			for _, name := range tt.restore {
+				want, wantExists := source.healthData.Get(name)
+				got, gotExists := restored.healthData.Get(name)
+				if gotExists != wantExists {
+					t.Fatalf("restoreHealthData(%s) exists = %v, want %v", name, gotExists, wantExists)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("restoreHealthData(%s) = %+v, want %+v", name, got, want)
+				}
+			}
+
+			if got := len(restored.GetAllHealthData()); got != tt.want {
+				t.Errorf("GetAllHealthData() length = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_LoadSnapshotInvalid(t *testing.T) {
+	hc := NewHealthChecker()
+	if err := hc.LoadSnapshot(strings.NewReader("{not json")); err == nil {
+		t.Errorf("LoadSnapshot() expected error for invalid JSON")
+	}
+}
+
+func TestHealthChecker_RestoreDoesNotOverwriteFreshData(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := CheckConfig{Name: "default/api", URL: "http://api.default.svc.cluster.local:8080/health"}
+
+	source := NewHealthChecker()
+	source.recordCheckResult(cfg, startTime, 500, nil)
+
+	var buf bytes.Buffer
+	if err := source.SaveSnapshot(&buf); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	restored := NewHealthChecker()
+	if err := restored.LoadSnapshot(&buf); err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	restored.recordCheckResult(cfg, startTime.Add(time.Minute), 200, nil)
+	restored.restoreHealthData(cfg.Name)
+
+	info, _ := restored.healthData.Get(cfg.Name)
+	if len(info.History) != 1 {
+		t.Errorf("restoreHealthData() history length = %v, want 1", len(info.History))
+	}
+	if info.Status != "healthy" {
+		t.Errorf("restoreHealthData() status = %v, want healthy", info.Status)
+	}
+}
@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// SortOrder is the order services are returned in by GetAllHealthDataSorted
+type SortOrder string
+
+const (
+	// SortByKey orders services by namespace, then name
+	SortByKey SortOrder = "key"
+	// SortByUptime puts the services with the lowest uptime first
+	SortByUptime SortOrder = "uptime"
+	// SortByLastCheck puts the most recently checked services first
+	SortByLastCheck SortOrder = "last_check"
+	// SortByStatus puts unhealthy services first, then unknown, maintenance and healthy
+	SortByStatus SortOrder = "status"
+)
+
+// statusRank orders statuses from worst to best
+var statusRank = map[types.HealthStatus]int{
+	types.HealthStatusUnhealthy:   0,
+	types.HealthStatusUnknown:     1,
+	types.HealthStatusMaintenance: 2,
+	types.HealthStatusHealthy:     3,
+}
+
+// ParseSortOrder parses a sort order. Empty is SortByKey.
+func ParseSortOrder(value string) (SortOrder, error) {
+	order := SortOrder(value)
+	switch order {
+	case "":
+		return SortByKey, nil
+	case SortByKey, SortByUptime, SortByLastCheck, SortByStatus:
+		return order, nil
+	}
+	return "", fmt.Errorf("unknown sort order %q", value)
+}
+
+// GetAllHealthDataSorted is GetAllHealthData in the given order. Services that
+// compare equal stay in key order.
+func (hc *HealthChecker) GetAllHealthDataSorted(order SortOrder) []*types.ServiceHealthInfo {
+	data := hc.GetAllHealthData()
+	SortHealthData(data, order)
+	return data
+}
+
+// SortHealthData sorts data in place. The sort is stable, so data already in
+// key order stays that way for SortByKey and for services that compare equal.
+func SortHealthData(data []*types.ServiceHealthInfo, order SortOrder) {
+	if order == SortByKey || order == "" {
+		return
+	}
+	slices.SortStableFunc(data, func(a, b *types.ServiceHealthInfo) int {
+		return compareBy(order, a, b)
+	})
+}
+
+func compareBy(order SortOrder, a, b *types.ServiceHealthInfo) int {
+	switch order {
+	case SortByUptime:
+		return cmp.Compare(a.Uptime, b.Uptime)
+	case SortByLastCheck:
+		return b.LastCheck.Compare(a.LastCheck)
+	case SortByStatus:
+		return cmp.Compare(rankOf(a.Status), rankOf(b.Status))
+	}
+	return 0
+}
+
+// rankOf ranks unrecognized statuses after healthy
+func rankOf(status types.HealthStatus) int {
+	rank, exists := statusRank[status]
+	if !exists {
+		return len(statusRank)
+	}
+	return rank
+}
@@ -0,0 +1,68 @@
+package healthcheck
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_GetAllHealthDataSorted(t *testing.T) {
+	now := time.Now()
+	hc := NewHealthChecker()
+	for _, info := range []*types.ServiceHealthInfo{
+		{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 99, LastCheck: now.Add(-3 * time.Second)},
+		{ServiceName: "db", Namespace: "default", Status: types.HealthStatusUnhealthy, Uptime: 40, LastCheck: now.Add(-time.Second)},
+		{ServiceName: "cache", Namespace: "prod", Status: types.HealthStatusUnknown, Uptime: 40, LastCheck: now},
+		{ServiceName: "web", Namespace: "prod", Status: types.HealthStatusMaintenance, Uptime: 80, LastCheck: now.Add(-2 * time.Second)},
+	} {
+		hc.healthData.Set(info.Namespace+"/"+info.ServiceName, info)
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{order: SortByKey, want: []string{"api", "db", "cache", "web"}},
+		{order: SortByUptime, want: []string{"db", "cache", "web", "api"}},
+		{order: SortByLastCheck, want: []string{"cache", "db", "web", "api"}},
+		{order: SortByStatus, want: []string{"db", "cache", "web", "api"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			var got []string
+			for _, info := range hc.GetAllHealthDataSorted(tt.order) {
+				got = append(got, info.ServiceName)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetAllHealthDataSorted(%q) = %v, want %v", tt.order, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SortOrder
+		wantErr bool
+	}{
+		{value: "", want: SortByKey},
+		{value: "uptime", want: SortByUptime},
+		{value: "last_check", want: SortByLastCheck},
+		{value: "status", want: SortByStatus},
+		{value: "name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSortOrder(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSortOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSortOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"sync"
+
+	"github.com/kdwils/constellation/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	stateNamespaces = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "constellation_state_namespaces",
+		Help: "Number of namespaces with at least one registered health target.",
+	})
+
+	stateTargets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "constellation_state_targets",
+		Help: "Number of registered health targets.",
+	})
+
+	stateChecks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "constellation_state_checks",
+		Help: "Number of checks run across all registered health targets.",
+	})
+
+	stateEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "constellation_state_events_total",
+		Help: "Number of registration events applied by type.",
+	}, []string{"type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stateNamespaces, stateTargets, stateChecks, stateEvents)
+}
+
+// stateStats keeps the gauges in step with registrations by applying the
+// change of every event, so scrapes never walk the registered targets
+type stateStats struct {
+	mu         sync.Mutex
+	namespaces map[string]int
+}
+
+func newStateStats() *stateStats {
+	return &stateStats{namespaces: make(map[string]int)}
+}
+
+// observe applies an event for the target name whose number of checks changed by checksDelta
+func (s *stateStats) observe(name string, eventType types.StateEventType, checksDelta int) {
+	stateEvents.WithLabelValues(string(eventType)).Inc()
+	stateChecks.Add(float64(checksDelta))
+
+	namespace, _ := parseTargetName(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch eventType {
+	case types.StateEventAdd:
+		stateTargets.Inc()
+		s.namespaces[namespace]++
+		if s.namespaces[namespace] == 1 {
+			stateNamespaces.Inc()
+		}
+	case types.StateEventDelete:
+		stateTargets.Dec()
+		s.namespaces[namespace]--
+		if s.namespaces[namespace] == 0 {
+			delete(s.namespaces, namespace)
+			stateNamespaces.Dec()
+		}
+	}
+}
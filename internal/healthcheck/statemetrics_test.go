@@ -0,0 +1,86 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHealthChecker_StateMetrics(t *testing.T) {
+	hc := NewHealthChecker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hc.listenForRegistrations(ctx)
+	go hc.listenForUnregistrations(ctx)
+
+	check := func(name, url string) CheckConfig {
+		return CheckConfig{Name: name, URL: url, Interval: time.Hour, Timeout: time.Second}
+	}
+
+	namespaces := testutil.ToFloat64(stateNamespaces)
+	targets := testutil.ToFloat64(stateTargets)
+	checks := testutil.ToFloat64(stateChecks)
+	events := map[types.StateEventType]float64{}
+	for _, eventType := range []types.StateEventType{types.StateEventAdd, types.StateEventUpdate, types.StateEventDelete} {
+		events[eventType] = testutil.ToFloat64(stateEvents.WithLabelValues(string(eventType)))
+	}
+
+	waitForEvents := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if len(hc.GetEvents(time.Time{})) >= n {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("GetEvents() did not reach %d events", n)
+	}
+
+	hc.RegisterHealthTarget("default/api", []CheckConfig{check("default/api", "http://api/healthz")})
+	waitForEvents(1)
+	hc.RegisterHealthTarget("default/web", []CheckConfig{check("default/web", "http://web/healthz")})
+	waitForEvents(2)
+	hc.RegisterHealthTarget("data/db", []CheckConfig{check("data/db", "tcp://db:5432")})
+	waitForEvents(3)
+	hc.RegisterHealthTarget("default/api", []CheckConfig{
+		check("default/api", "http://api/healthz"),
+		check("default/api", "http://api/ready"),
+	})
+	waitForEvents(4)
+	hc.UnregisterHealthTarget("data/db")
+	waitForEvents(5)
+
+	gauges := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "namespaces", got: testutil.ToFloat64(stateNamespaces) - namespaces, want: 1},
+		{name: "targets", got: testutil.ToFloat64(stateTargets) - targets, want: 2},
+		{name: "checks", got: testutil.ToFloat64(stateChecks) - checks, want: 3},
+		{
+			name: "add events",
+			got:  testutil.ToFloat64(stateEvents.WithLabelValues(string(types.StateEventAdd))) - events[types.StateEventAdd],
+			want: 3,
+		},
+		{
+			name: "update events",
+			got:  testutil.ToFloat64(stateEvents.WithLabelValues(string(types.StateEventUpdate))) - events[types.StateEventUpdate],
+			want: 1,
+		},
+		{
+			name: "delete events",
+			got:  testutil.ToFloat64(stateEvents.WithLabelValues(string(types.StateEventDelete))) - events[types.StateEventDelete],
+			want: 1,
+		},
+	}
+	for _, g := range gauges {
+		if g.got != g.want {
+			t.Errorf("%s = %v, want %v", g.name, g.got, g.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package healthcheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+)
+
+// errInvalidCABundle is returned when a check's CA bundle holds no usable certificates
+var errInvalidCABundle = errors.New("ca bundle contains no valid PEM certificates")
+
+// tlsKey identifies a TLS configuration so checks sharing one share a transport
+type tlsKey struct {
+	insecureSkipVerify bool
+	caBundle           string
+}
+
+// clientFor returns the client a check is sent through. Checks without TLS
+// settings use the shared client; the rest get a client per distinct TLS
+// configuration, built once and reused.
+func (hc *HealthChecker) clientFor(cfg CheckConfig) (HTTPClient, error) {
+	if !cfg.InsecureSkipVerify && cfg.CABundle == "" {
+		return hc.httpClient, nil
+	}
+
+	key := tlsKey{insecureSkipVerify: cfg.InsecureSkipVerify, caBundle: cfg.CABundle}
+	hc.tlsMu.Lock()
+	defer hc.tlsMu.Unlock()
+	if client, ok := hc.tlsClients[key]; ok {
+		return client, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CABundle)) {
+			return nil, errInvalidCABundle
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	client := newDefaultHTTPClient()
+	client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	hc.tlsClients[key] = client
+	return client, nil
+}
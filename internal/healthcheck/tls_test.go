@@ -0,0 +1,97 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestHealthChecker_TLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	tests := []struct {
+		name               string
+		insecureSkipVerify bool
+		caBundle           string
+		wantStatus         types.HealthStatus
+	}{
+		{
+			name:       "untrusted certificate fails verification",
+			wantStatus: "unhealthy",
+		},
+		{
+			name:               "skip verify accepts untrusted certificate",
+			insecureSkipVerify: true,
+			wantStatus:         "healthy",
+		},
+		{
+			name:       "ca bundle trusts server certificate",
+			caBundle:   serverCA,
+			wantStatus: "healthy",
+		},
+		{
+			name:       "invalid ca bundle",
+			caBundle:   "not a certificate",
+			wantStatus: "unhealthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:               "default/api",
+				URL:                server.URL,
+				Timeout:            time.Second,
+				Protocol:           "https",
+				InsecureSkipVerify: tt.insecureSkipVerify,
+				CABundle:           tt.caBundle,
+			}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData(cfg.Name)
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, tt.wantStatus, info.History[0].Error)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_ClientForCachesByTLSConfig(t *testing.T) {
+	hc := NewHealthChecker()
+
+	shared, err := hc.clientFor(CheckConfig{})
+	if err != nil {
+		t.Fatalf("clientFor() error = %v", err)
+	}
+	if shared != hc.httpClient {
+		t.Errorf("clientFor() without TLS settings did not return the shared client")
+	}
+
+	first, err := hc.clientFor(CheckConfig{URL: "https://a", InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("clientFor() error = %v", err)
+	}
+	second, err := hc.clientFor(CheckConfig{URL: "https://b", InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("clientFor() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("clientFor() built a new client for an identical TLS config")
+	}
+	if len(hc.tlsClients) != 1 {
+		t.Errorf("clientFor() cached %v clients, want 1", len(hc.tlsClients))
+	}
+}
@@ -0,0 +1,38 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// maxDatagramBytes is large enough for any reply over a standard MTU
+const maxDatagramBytes = 1500
+
+// checkUDP sends payload to "udp://host:port" and waits for any reply until
+// ctx is done. UDP is connectionless, so silence and ICMP port unreachable
+// are the only failures that can be observed.
+func checkUDP(ctx context.Context, target, payload string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", strings.TrimPrefix(target, "udp://"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		return err
+	}
+	buf := make([]byte, maxDatagramBytes)
+	if _, err := conn.Read(buf); err != nil {
+		return fmt.Errorf("no udp reply: %w", err)
+	}
+	return nil
+}
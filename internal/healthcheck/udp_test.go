@@ -0,0 +1,93 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func newUDPServer(t *testing.T, reply bool) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxDatagramBytes)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply {
+				_, _ = conn.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func closedUDPAddr(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+	return addr
+}
+
+func TestHealthChecker_UDPCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       func(t *testing.T) string
+		payload    string
+		wantStatus types.HealthStatus
+	}{
+		{
+			name:       "echo reply is healthy",
+			addr:       func(t *testing.T) string { return newUDPServer(t, true) },
+			payload:    "ping",
+			wantStatus: types.HealthStatusHealthy,
+		},
+		{
+			name:       "silent server is unhealthy",
+			addr:       func(t *testing.T) string { return newUDPServer(t, false) },
+			payload:    "ping",
+			wantStatus: types.HealthStatusUnhealthy,
+		},
+		{
+			name:       "closed port is unhealthy",
+			addr:       closedUDPAddr,
+			payload:    "ping",
+			wantStatus: types.HealthStatusUnhealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthChecker()
+			cfg := CheckConfig{
+				Name:     "default/dns",
+				URL:      "udp://" + tt.addr(t),
+				Protocol: "udp",
+				Payload:  tt.payload,
+				Timeout:  200 * time.Millisecond,
+			}
+			hc.executeCheck(context.Background(), cfg)
+
+			info, ok := hc.GetHealthData("default/dns")
+			if !ok {
+				t.Fatalf("GetHealthData() ok = false, want true")
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("executeCheck() status = %v, want %v (error %q)", info.Status, tt.wantStatus, info.History[0].Error)
+			}
+		})
+	}
+}
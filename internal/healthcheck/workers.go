@@ -0,0 +1,40 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultWorkers caps how many checks run at once, which bounds the number of
+// outbound connections on clusters with many targets
+const defaultWorkers = 64
+
+// WithWorkers sets how many checks may run at once. Checks that come due while
+// every worker is busy wait in the queue. Zero or less runs each check on its
+// own goroutine.
+func WithWorkers(n int) HealthCheckerOpt {
+	return func(hc *HealthChecker) {
+		hc.workers = n
+	}
+}
+
+// startWorkers runs the worker pool until ctx is done. The returned WaitGroup
+// finishes once every in-flight check has returned.
+func (hc *HealthChecker) startWorkers(ctx context.Context) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for range hc.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case check := <-hc.checkCh:
+					hc.runQueued(ctx, check)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return &wg
+}
@@ -0,0 +1,71 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker_Workers(t *testing.T) {
+	tests := []struct {
+		name        string
+		workers     int
+		checks      int
+		wantMaxBusy int32
+	}{
+		{name: "single worker runs checks one at a time", workers: 1, checks: 5, wantMaxBusy: 1},
+		{name: "concurrency is capped at the pool size", workers: 3, checks: 20, wantMaxBusy: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var busy, maxBusy, served atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := busy.Add(1)
+				defer busy.Add(-1)
+				for {
+					current := maxBusy.Load()
+					if n <= current || maxBusy.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				served.Add(1)
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker(WithWorkers(tt.workers))
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				hc.Start(ctx)
+			}()
+
+			for i := range tt.checks {
+				hc.dispatch(ctx, CheckConfig{Name: fmt.Sprintf("default/svc-%d", i), URL: server.URL, Timeout: time.Second})
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) && served.Load() < int32(tt.checks) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := served.Load(); got != int32(tt.checks) {
+				t.Errorf("served %v checks, want %v", got, tt.checks)
+			}
+			if got := maxBusy.Load(); got != tt.wantMaxBusy {
+				t.Errorf("max concurrent checks = %v, want %v", got, tt.wantMaxBusy)
+			}
+
+			cancel()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Start() did not return after cancel")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package hierarchy
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// OpType is the change an Op makes to a tree
+type OpType string
+
+const (
+	// OpUpsert adds the node at Path or replaces it along with its relatives
+	OpUpsert OpType = "upsert"
+	// OpDelete removes the node at Path and its relatives
+	OpDelete OpType = "delete"
+)
+
+// Op is a change to a single node, addressed by the Kind/Name segments from
+// the root down to it, e.g. ["Namespace/default", "Group/payments", "Service/api"]
+type Op struct {
+	Op   OpType               `json:"op"`
+	Path []string             `json:"path"`
+	Node *types.HierarchyNode `json:"node,omitempty"`
+}
+
+// PathSegment is how a node is addressed among its siblings. Kind is part of
+// it because a group and a service in the same namespace may share a name.
+func PathSegment(node types.HierarchyNode) string {
+	return string(node.Kind) + "/" + node.Name
+}
+
+// Diff returns the ops that turn old into updated. Nodes whose own fields are
+// unchanged are descended into, so an update to one service does not resend
+// its namespace.
+func Diff(old, updated []types.HierarchyNode) []Op {
+	return diffLevel(nil, old, updated)
+}
+
+func diffLevel(parent []string, old, updated []types.HierarchyNode) []Op {
+	var ops []Op
+	previous := make(map[string]types.HierarchyNode, len(old))
+	for _, node := range old {
+		previous[PathSegment(node)] = node
+	}
+
+	for _, node := range updated {
+		segment := PathSegment(node)
+		path := append(slices.Clone(parent), segment)
+		existing, exists := previous[segment]
+		delete(previous, segment)
+
+		if !exists || !sameNode(existing, node) {
+			upserted := node
+			ops = append(ops, Op{Op: OpUpsert, Path: path, Node: &upserted})
+			continue
+		}
+		ops = append(ops, diffLevel(path, existing.Relatives, node.Relatives)...)
+	}
+
+	for _, node := range old {
+		if _, removed := previous[PathSegment(node)]; removed {
+			ops = append(ops, Op{Op: OpDelete, Path: append(slices.Clone(parent), PathSegment(node))})
+		}
+	}
+	return ops
+}
+
+// sameNode compares nodes without their relatives
+func sameNode(a, b types.HierarchyNode) bool {
+	a.Relatives, b.Relatives = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// Apply returns nodes with ops applied, keeping siblings in the order Build
+// produces. Ops whose parent is missing are ignored.
+func Apply(nodes []types.HierarchyNode, ops []Op) []types.HierarchyNode {
+	for _, op := range ops {
+		nodes = applyOp(nodes, op.Path, op)
+	}
+	return nodes
+}
+
+func applyOp(nodes []types.HierarchyNode, path []string, op Op) []types.HierarchyNode {
+	if len(path) == 0 {
+		return nodes
+	}
+
+	i := slices.IndexFunc(nodes, func(n types.HierarchyNode) bool { return PathSegment(n) == path[0] })
+	if len(path) > 1 {
+		if i < 0 {
+			return nodes
+		}
+		nodes = slices.Clone(nodes)
+		nodes[i].Relatives = applyOp(nodes[i].Relatives, path[1:], op)
+		return nodes
+	}
+
+	if op.Op == OpDelete {
+		if i < 0 {
+			return nodes
+		}
+		return slices.Delete(slices.Clone(nodes), i, i+1)
+	}
+	if op.Node == nil {
+		return nodes
+	}
+
+	nodes = slices.Clone(nodes)
+	if i < 0 {
+		nodes = append(nodes, *op.Node)
+	}
+	if i >= 0 {
+		nodes[i] = *op.Node
+	}
+	slices.SortStableFunc(nodes, compareNodes)
+	return nodes
+}
@@ -0,0 +1,100 @@
+package hierarchy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kdwils/constellation/internal/hierarchy"
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     []*types.ServiceHealthInfo
+		updated []*types.ServiceHealthInfo
+		want    []hierarchy.Op
+	}{
+		{
+			name:    "unchanged",
+			old:     []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}},
+			updated: []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}},
+		},
+		{
+			name: "service health changed",
+			old:  []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}},
+			updated: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Status: types.HealthStatusUnhealthy},
+			},
+			want: []hierarchy.Op{{Op: hierarchy.OpUpsert, Path: []string{"Namespace/default", "Service/api"}}},
+		},
+		{
+			name: "service removed",
+			old: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default"},
+				{ServiceName: "web", Namespace: "default"},
+			},
+			updated: []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}},
+			want:    []hierarchy.Op{{Op: hierarchy.OpDelete, Path: []string{"Namespace/default", "Service/web"}}},
+		},
+		{
+			name:    "namespace added",
+			old:     []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}},
+			updated: []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default"}, {ServiceName: "db", Namespace: "data"}},
+			want:    []hierarchy.Op{{Op: hierarchy.OpUpsert, Path: []string{"Namespace/data"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := hierarchy.Diff(hierarchy.BuildGrouped(tt.old), hierarchy.BuildGrouped(tt.updated))
+			if len(ops) != len(tt.want) {
+				t.Fatalf("Diff() = %d ops, want %d", len(ops), len(tt.want))
+			}
+			for i, op := range ops {
+				if op.Op != tt.want[i].Op || !reflect.DeepEqual(op.Path, tt.want[i].Path) {
+					t.Errorf("Diff() op %d = %v %v, want %v %v", i, op.Op, op.Path, tt.want[i].Op, tt.want[i].Path)
+				}
+			}
+		})
+	}
+}
+
+func TestApply_ReconstructsSnapshot(t *testing.T) {
+	steps := [][]*types.ServiceHealthInfo{
+		{
+			{ServiceName: "api", Namespace: "default"},
+		},
+		{
+			{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy},
+			{ServiceName: "web", Namespace: "default", Group: "frontend"},
+		},
+		{
+			{ServiceName: "api", Namespace: "default", Group: "frontend"},
+			{ServiceName: "web", Namespace: "default", Group: "frontend", Status: types.HealthStatusUnhealthy},
+			{ServiceName: "db", Namespace: "data"},
+		},
+		{
+			{ServiceName: "frontend", Namespace: "default"},
+			{ServiceName: "web", Namespace: "default", Group: "frontend/edge"},
+		},
+		{},
+		{
+			{ServiceName: "api", Namespace: "default"},
+		},
+	}
+
+	var client []types.HierarchyNode
+	var previous []types.HierarchyNode
+	for i, data := range steps {
+		snapshot := hierarchy.BuildGrouped(data)
+		client = hierarchy.Apply(client, hierarchy.Diff(previous, snapshot))
+		previous = snapshot
+
+		if len(snapshot) == 0 && len(client) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(client, snapshot) {
+			t.Errorf("Apply() step %d = %+v, want %+v", i, client, snapshot)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package hierarchy arranges flat per-service health data into a
+// namespace/service tree and renders it for terminal output.
+package hierarchy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// Build nests services under their namespace. Nodes are sorted at every level
+// so the tree is the same regardless of the order of data.
+func Build(data []*types.ServiceHealthInfo) []types.HierarchyNode {
+	var nodes []types.HierarchyNode
+	index := make(map[string]int)
+
+	for _, info := range data {
+		i, exists := index[info.Namespace]
+		if !exists {
+			i = len(nodes)
+			index[info.Namespace] = i
+			nodes = append(nodes, types.HierarchyNode{
+				Kind: types.ResourceKindNamespace,
+				Name: info.Namespace,
+			})
+		}
+
+		namespace := info.Namespace
+		nodes[i].Relatives = append(nodes[i].Relatives, types.HierarchyNode{
+			Kind:         types.ResourceKindService,
+			Name:         info.ServiceName,
+			Namespace:    &namespace,
+			Group:        info.Group,
+			ExternalName: info.ExternalName,
+			HealthInfo:   info,
+		})
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// BuildGrouped is Build with services nested under synthetic Group nodes
+// within their namespace. A group of "platform/observability" puts the service
+// under an observability group inside a platform group. Ungrouped services stay
+// directly under the namespace.
+func BuildGrouped(data []*types.ServiceHealthInfo) []types.HierarchyNode {
+	nodes := Build(data)
+	for i := range nodes {
+		var relatives []types.HierarchyNode
+		for _, service := range nodes[i].Relatives {
+			relatives = insertGrouped(relatives, groupPath(service.Group), service)
+		}
+		nodes[i].Relatives = relatives
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// sortNodes orders nodes and their relatives by kind, name and namespace
+func sortNodes(nodes []types.HierarchyNode) {
+	slices.SortStableFunc(nodes, compareNodes)
+	for i := range nodes {
+		sortNodes(nodes[i].Relatives)
+	}
+}
+
+func compareNodes(a, b types.HierarchyNode) int {
+	if c := strings.Compare(string(a.Kind), string(b.Kind)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	return strings.Compare(namespaceOf(a), namespaceOf(b))
+}
+
+func namespaceOf(node types.HierarchyNode) string {
+	if node.Namespace == nil {
+		return ""
+	}
+	return *node.Namespace
+}
+
+// groupPath splits a group into its nested names, ignoring empty segments
+// left by leading, trailing or doubled slashes
+func groupPath(group string) []string {
+	var path []string
+	for _, name := range strings.Split(group, "/") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+func insertGrouped(nodes []types.HierarchyNode, path []string, service types.HierarchyNode) []types.HierarchyNode {
+	if len(path) == 0 {
+		return append(nodes, service)
+	}
+
+	i := slices.IndexFunc(nodes, func(n types.HierarchyNode) bool {
+		return n.Kind == types.ResourceKindGroup && n.Name == path[0]
+	})
+	if i < 0 {
+		i = len(nodes)
+		nodes = append(nodes, types.HierarchyNode{
+			Kind:      types.ResourceKindGroup,
+			Name:      path[0],
+			Namespace: service.Namespace,
+		})
+	}
+	nodes[i].Relatives = insertGrouped(nodes[i].Relatives, path[1:], service)
+	return nodes
+}
+
+// BuildConnections returns an edge from every node to each of its relatives,
+// for graph views that only need the tree's shape
+func BuildConnections(nodes []types.HierarchyNode) []types.Connection {
+	connections := []types.Connection{}
+	for _, node := range nodes {
+		connections = appendConnections(connections, node, NodeID(node, ""))
+	}
+	return connections
+}
+
+func appendConnections(connections []types.Connection, node types.HierarchyNode, id string) []types.Connection {
+	for _, relative := range node.Relatives {
+		relativeID := NodeID(relative, id)
+		connections = append(connections, types.Connection{Source: id, Target: relativeID})
+		connections = appendConnections(connections, relative, relativeID)
+	}
+	return connections
+}
+
+// BuildClusterState flattens nodes into a graph: every node keyed by its
+// NodeID, plus the edges from BuildConnections
+func BuildClusterState(nodes []types.HierarchyNode) types.ClusterState {
+	state := types.ClusterState{
+		Resources:   make(map[string]types.Resource),
+		Connections: BuildConnections(nodes),
+	}
+	for _, node := range nodes {
+		addResources(state.Resources, node, NodeID(node, ""))
+	}
+	return state
+}
+
+func addResources(resources map[string]types.Resource, node types.HierarchyNode, id string) {
+	resources[id] = types.Resource{
+		Kind:      node.Kind,
+		Name:      node.Name,
+		Namespace: namespaceOf(node),
+		Metadata: types.ResourceMetadata{
+			Hostnames:       node.Hostnames,
+			Selectors:       node.Selectors,
+			Ports:           node.Ports,
+			PortMappings:    node.PortMappings,
+			TargetPorts:     node.TargetPorts,
+			TargetPortNames: node.TargetPortNames,
+			Labels:          node.Labels,
+			Phase:           node.Phase,
+			BackendRefs:     node.BackendRefs,
+			ServiceType:     node.ServiceType,
+			ExternalName:    node.ExternalName,
+			ClusterIPs:      node.ClusterIPs,
+			ExternalIPs:     node.ExternalIPs,
+			PodIPs:          node.PodIPs,
+			ContainerPorts:  node.ContainerPorts,
+			Group:           node.Group,
+			DisplayName:     node.DisplayName,
+			Ignore:          node.Ignore,
+		},
+	}
+	for _, relative := range node.Relatives {
+		addResources(resources, relative, NodeID(relative, id))
+	}
+}
+
+// NodeID identifies a node across updates. Groups only exist within their
+// parent, so their ID is nested under parentID.
+func NodeID(node types.HierarchyNode, parentID string) string {
+	switch {
+	case node.Kind == types.ResourceKindGroup:
+		return parentID + "/" + string(node.Kind) + "/" + node.Name
+	case node.Namespace != nil:
+		return string(node.Kind) + "/" + *node.Namespace + "/" + node.Name
+	default:
+		return string(node.Kind) + "/" + node.Name
+	}
+}
+
+// RenderTree writes nodes as an indented tree, one node per line
+func RenderTree(w io.Writer, nodes []types.HierarchyNode) error {
+	var b strings.Builder
+	for _, node := range nodes {
+		renderNode(&b, node, 0)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func renderNode(b *strings.Builder, node types.HierarchyNode, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(b, "%s/%s", node.Kind, node.Name)
+
+	var details []string
+	if node.Namespace != nil {
+		details = append(details, "namespace="+*node.Namespace)
+	}
+	if node.Phase != nil {
+		details = append(details, "phase="+*node.Phase)
+	}
+	if node.HealthInfo != nil {
+		details = append(details, "status="+string(node.HealthInfo.Status))
+		details = append(details, fmt.Sprintf("uptime=%.1f%%", node.HealthInfo.Uptime))
+	}
+	if len(details) > 0 {
+		b.WriteString(" " + strings.Join(details, " "))
+	}
+	b.WriteString("\n")
+
+	for _, relative := range node.Relatives {
+		renderNode(b, relative, depth+1)
+	}
+}
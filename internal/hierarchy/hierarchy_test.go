@@ -0,0 +1,325 @@
+package hierarchy_test
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kdwils/constellation/internal/hierarchy"
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestRenderTree(t *testing.T) {
+	running := "Running"
+	tests := []struct {
+		name  string
+		nodes []types.HierarchyNode
+		want  string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "services from health data",
+			nodes: hierarchy.Build([]*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 100},
+				{ServiceName: "web", Namespace: "default", Status: types.HealthStatusUnhealthy, Uptime: 42.5},
+				{ServiceName: "db", Namespace: "data", Status: types.HealthStatusUnknown},
+			}),
+			want: "Namespace/data\n" +
+				"  Service/db namespace=data status=unknown uptime=0.0%\n" +
+				"Namespace/default\n" +
+				"  Service/api namespace=default status=healthy uptime=100.0%\n" +
+				"  Service/web namespace=default status=unhealthy uptime=42.5%\n",
+		},
+		{
+			name: "nested pods with phase",
+			nodes: []types.HierarchyNode{{
+				Kind: types.ResourceKindNamespace,
+				Name: "default",
+				Relatives: []types.HierarchyNode{{
+					Kind: types.ResourceKindService,
+					Name: "api",
+					Relatives: []types.HierarchyNode{{
+						Kind:  types.ResourceKindPod,
+						Name:  "api-0",
+						Phase: &running,
+					}},
+				}},
+			}},
+			want: "Namespace/default\n" +
+				"  Service/api\n" +
+				"    Pod/api-0 phase=Running\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := hierarchy.RenderTree(&b, tt.nodes); err != nil {
+				t.Fatalf("RenderTree() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("RenderTree() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildGrouped(t *testing.T) {
+	tests := []struct {
+		name string
+		data []*types.ServiceHealthInfo
+		want string
+	}{
+		{
+			name: "ungrouped services stay under the namespace",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Status: types.HealthStatusHealthy},
+			},
+			want: "Namespace/default\n" +
+				"  Service/api namespace=default status=healthy uptime=0.0%\n",
+		},
+		{
+			name: "flat groups",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "default", Group: "backend", Status: types.HealthStatusHealthy},
+				{ServiceName: "web", Namespace: "default", Group: "frontend", Status: types.HealthStatusHealthy},
+				{ServiceName: "worker", Namespace: "default", Group: "backend", Status: types.HealthStatusHealthy},
+				{ServiceName: "cron", Namespace: "default", Status: types.HealthStatusHealthy},
+			},
+			want: "Namespace/default\n" +
+				"  Group/backend namespace=default\n" +
+				"    Service/api namespace=default status=healthy uptime=0.0%\n" +
+				"    Service/worker namespace=default status=healthy uptime=0.0%\n" +
+				"  Group/frontend namespace=default\n" +
+				"    Service/web namespace=default status=healthy uptime=0.0%\n" +
+				"  Service/cron namespace=default status=healthy uptime=0.0%\n",
+		},
+		{
+			name: "nested groups",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "grafana", Namespace: "monitoring", Group: "platform/observability",
+					Status: types.HealthStatusHealthy},
+				{ServiceName: "ingress", Namespace: "monitoring", Group: "platform",
+					Status: types.HealthStatusHealthy},
+				{ServiceName: "loki", Namespace: "monitoring", Group: "/platform//observability/",
+					Status: types.HealthStatusHealthy},
+			},
+			want: "Namespace/monitoring\n" +
+				"  Group/platform namespace=monitoring\n" +
+				"    Group/observability namespace=monitoring\n" +
+				"      Service/grafana namespace=monitoring status=healthy uptime=0.0%\n" +
+				"      Service/loki namespace=monitoring status=healthy uptime=0.0%\n" +
+				"    Service/ingress namespace=monitoring status=healthy uptime=0.0%\n",
+		},
+		{
+			name: "same group name in different namespaces",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "api", Namespace: "a", Group: "backend", Status: types.HealthStatusHealthy},
+				{ServiceName: "api", Namespace: "b", Group: "backend", Status: types.HealthStatusHealthy},
+			},
+			want: "Namespace/a\n" +
+				"  Group/backend namespace=a\n" +
+				"    Service/api namespace=a status=healthy uptime=0.0%\n" +
+				"Namespace/b\n" +
+				"  Group/backend namespace=b\n" +
+				"    Service/api namespace=b status=healthy uptime=0.0%\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := hierarchy.RenderTree(&b, hierarchy.BuildGrouped(tt.data)); err != nil {
+				t.Fatalf("RenderTree() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("BuildGrouped() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_SortsRelatives(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "web", Namespace: "prod", Group: "platform/edge"},
+		{ServiceName: "api", Namespace: "prod"},
+		{ServiceName: "zeta", Namespace: "dev"},
+		{ServiceName: "cache", Namespace: "prod", Group: "platform"},
+		{ServiceName: "alpha", Namespace: "dev"},
+		{ServiceName: "auth", Namespace: "prod", Group: "platform/edge"},
+		{ServiceName: "db", Namespace: "prod", Group: "data"},
+	}
+
+	tests := []struct {
+		name  string
+		build func([]*types.ServiceHealthInfo) []types.HierarchyNode
+		want  string
+	}{
+		{
+			name:  "flat",
+			build: hierarchy.Build,
+			want: "Namespace/dev\n" +
+				"  Service/alpha namespace=dev\n" +
+				"  Service/zeta namespace=dev\n" +
+				"Namespace/prod\n" +
+				"  Service/api namespace=prod\n" +
+				"  Service/auth namespace=prod\n" +
+				"  Service/cache namespace=prod\n" +
+				"  Service/db namespace=prod\n" +
+				"  Service/web namespace=prod\n",
+		},
+		{
+			name:  "grouped",
+			build: hierarchy.BuildGrouped,
+			want: "Namespace/dev\n" +
+				"  Service/alpha namespace=dev\n" +
+				"  Service/zeta namespace=dev\n" +
+				"Namespace/prod\n" +
+				"  Group/data namespace=prod\n" +
+				"    Service/db namespace=prod\n" +
+				"  Group/platform namespace=prod\n" +
+				"    Group/edge namespace=prod\n" +
+				"      Service/auth namespace=prod\n" +
+				"      Service/web namespace=prod\n" +
+				"    Service/cache namespace=prod\n" +
+				"  Service/api namespace=prod\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := tt.build(data)
+			stripHealth(nodes)
+
+			var b strings.Builder
+			if err := hierarchy.RenderTree(&b, nodes); err != nil {
+				t.Fatalf("RenderTree() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("%s() =\n%s\nwant\n%s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// stripHealth drops health details so rendered trees only show structure
+func stripHealth(nodes []types.HierarchyNode) {
+	for i := range nodes {
+		nodes[i].HealthInfo = nil
+		stripHealth(nodes[i].Relatives)
+	}
+}
+
+func TestBuildConnections(t *testing.T) {
+	tests := []struct {
+		name string
+		data []*types.ServiceHealthInfo
+		want []types.Connection
+	}{
+		{
+			name: "empty",
+			want: []types.Connection{},
+		},
+		{
+			name: "services under namespaces",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "web", Namespace: "default"},
+				{ServiceName: "db", Namespace: "data"},
+			},
+			want: []types.Connection{
+				{Source: "Namespace/data", Target: "Service/data/db"},
+				{Source: "Namespace/default", Target: "Service/default/web"},
+			},
+		},
+		{
+			name: "nested groups",
+			data: []*types.ServiceHealthInfo{
+				{ServiceName: "grafana", Namespace: "monitoring", Group: "platform/observability"},
+				{ServiceName: "api", Namespace: "monitoring"},
+			},
+			want: []types.Connection{
+				{Source: "Namespace/monitoring", Target: "Namespace/monitoring/Group/platform"},
+				{
+					Source: "Namespace/monitoring/Group/platform",
+					Target: "Namespace/monitoring/Group/platform/Group/observability",
+				},
+				{
+					Source: "Namespace/monitoring/Group/platform/Group/observability",
+					Target: "Service/monitoring/grafana",
+				},
+				{Source: "Namespace/monitoring", Target: "Service/monitoring/api"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hierarchy.BuildConnections(hierarchy.BuildGrouped(tt.data))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("BuildConnections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildClusterState(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "grafana", Namespace: "monitoring", Group: "platform"},
+		{ServiceName: "api", Namespace: "default"},
+	}
+
+	got := hierarchy.BuildClusterState(hierarchy.BuildGrouped(data))
+
+	wantResources := map[string]types.Resource{
+		"Namespace/default":                   {Kind: types.ResourceKindNamespace, Name: "default"},
+		"Service/default/api":                 {Kind: types.ResourceKindService, Name: "api", Namespace: "default"},
+		"Namespace/monitoring":                {Kind: types.ResourceKindNamespace, Name: "monitoring"},
+		"Namespace/monitoring/Group/platform": {Kind: types.ResourceKindGroup, Name: "platform", Namespace: "monitoring"},
+		"Service/monitoring/grafana": {
+			Kind:      types.ResourceKindService,
+			Name:      "grafana",
+			Namespace: "monitoring",
+			Metadata:  types.ResourceMetadata{Group: "platform"},
+		},
+	}
+	if !reflect.DeepEqual(got.Resources, wantResources) {
+		t.Errorf("BuildClusterState() resources = %v, want %v", got.Resources, wantResources)
+	}
+
+	wantConnections := []types.Connection{
+		{Source: "Namespace/default", Target: "Service/default/api"},
+		{Source: "Namespace/monitoring", Target: "Namespace/monitoring/Group/platform"},
+		{Source: "Namespace/monitoring/Group/platform", Target: "Service/monitoring/grafana"},
+	}
+	if !slices.Equal(got.Connections, wantConnections) {
+		t.Errorf("BuildClusterState() connections = %v, want %v", got.Connections, wantConnections)
+	}
+
+	// Input order must not change the result
+	reversed := hierarchy.BuildClusterState(hierarchy.BuildGrouped([]*types.ServiceHealthInfo{data[1], data[0]}))
+	if !reflect.DeepEqual(reversed, got) {
+		t.Errorf("BuildClusterState() = %v for reversed input, want %v", reversed, got)
+	}
+}
+
+func TestBuild_ExternalName(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "billing", Namespace: "prod", ExternalName: "billing.example.com"},
+	}
+
+	nodes := hierarchy.Build(data)
+	if len(nodes) != 1 || len(nodes[0].Relatives) != 1 {
+		t.Fatalf("Build() = %v, want one namespace with one service", nodes)
+	}
+	service := nodes[0].Relatives[0]
+	if service.ExternalName != "billing.example.com" {
+		t.Errorf("Build() external name = %q, want billing.example.com", service.ExternalName)
+	}
+	if len(service.Relatives) != 0 {
+		t.Errorf("Build() relatives = %v, want none", service.Relatives)
+	}
+
+	state := hierarchy.BuildClusterState(nodes)
+	if got := state.Resources["Service/prod/billing"].Metadata.ExternalName; got != "billing.example.com" {
+		t.Errorf("BuildClusterState() external name = %q, want billing.example.com", got)
+	}
+}
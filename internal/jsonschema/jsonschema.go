@@ -0,0 +1,117 @@
+// Package jsonschema derives JSON Schema documents from Go types by reflection,
+// following their json tags, so the published schema cannot drift from the API.
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// Schema is a JSON Schema object
+type Schema map[string]any
+
+// Document returns a schema whose $defs hold every named struct reachable
+// from roots, keyed by type name. Roots are listed under properties by name
+// so tools can find the entry points.
+func Document(roots ...reflect.Type) Schema {
+	g := &generator{defs: Schema{}}
+	properties := Schema{}
+	for _, root := range roots {
+		properties[root.Name()] = g.schemaFor(root)
+	}
+	return Schema{
+		"$schema":    draft,
+		"$defs":      g.defs,
+		"properties": properties,
+	}
+}
+
+type generator struct {
+	defs Schema
+}
+
+func (g *generator) schemaFor(t reflect.Type) Schema {
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return Schema{"type": "integer", "description": "duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		return Schema{}
+	}
+}
+
+// structRef defines t once under $defs, which also ends recursion for types
+// such as HierarchyNode that contain themselves
+func (g *generator) structRef(t reflect.Type) Schema {
+	ref := Schema{"$ref": "#/$defs/" + t.Name()}
+	if _, defined := g.defs[t.Name()]; defined {
+		return ref
+	}
+	g.defs[t.Name()] = Schema{}
+
+	properties := Schema{}
+	var required []string
+	for _, field := range reflect.VisibleFields(t) {
+		name, omitempty, ok := FieldName(field)
+		if !ok {
+			continue
+		}
+		properties[name] = g.schemaFor(field.Type)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	def := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	g.defs[t.Name()] = def
+	return ref
+}
+
+// FieldName returns the JSON name of an exported struct field and whether it
+// is omitempty. ok is false for fields encoding/json skips.
+func FieldName(field reflect.StructField) (name string, omitempty bool, ok bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
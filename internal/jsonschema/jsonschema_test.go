@@ -0,0 +1,72 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name      string            `json:"name"`
+	Parent    *node             `json:"parent,omitempty"`
+	Children  []node            `json:"children,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Seen      time.Time         `json:"seen"`
+	Latency   time.Duration     `json:"latency"`
+	Untagged  int
+	Secret    string `json:"-"`
+	unexposed bool
+}
+
+func TestDocument(t *testing.T) {
+	doc := Document(reflect.TypeFor[node]())
+	defs := doc["$defs"].(Schema)
+
+	want := Schema{
+		"type": "object",
+		"properties": Schema{
+			"name":     Schema{"type": "string"},
+			"parent":   Schema{"$ref": "#/$defs/node"},
+			"children": Schema{"type": "array", "items": Schema{"$ref": "#/$defs/node"}},
+			"labels":   Schema{"type": "object", "additionalProperties": Schema{"type": "string"}},
+			"seen":     Schema{"type": "string", "format": "date-time"},
+			"latency":  Schema{"type": "integer", "description": "duration in nanoseconds"},
+			"Untagged": Schema{"type": "integer"},
+		},
+		"required": []string{"name", "seen", "latency", "Untagged"},
+	}
+	if !reflect.DeepEqual(defs["node"], want) {
+		t.Errorf("Document() node = %v, want %v", defs["node"], want)
+	}
+
+	root := doc["properties"].(Schema)["node"]
+	if !reflect.DeepEqual(root, Schema{"$ref": "#/$defs/node"}) {
+		t.Errorf("Document() root = %v, want a ref to node", root)
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	typ := reflect.TypeFor[node]()
+	tests := []struct {
+		field         string
+		wantName      string
+		wantOmitempty bool
+		wantOK        bool
+	}{
+		{field: "Name", wantName: "name", wantOK: true},
+		{field: "Parent", wantName: "parent", wantOmitempty: true, wantOK: true},
+		{field: "Untagged", wantName: "Untagged", wantOK: true},
+		{field: "Secret"},
+		{field: "unexposed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field, _ := typ.FieldByName(tt.field)
+			name, omitempty, ok := FieldName(field)
+			if name != tt.wantName || omitempty != tt.wantOmitempty || ok != tt.wantOK {
+				t.Errorf("FieldName() = %v, %v, %v, want %v, %v, %v",
+					name, omitempty, ok, tt.wantName, tt.wantOmitempty, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// shutdownTimeout bounds how long shutdown waits for clients to answer the close handshake
+const shutdownTimeout = 5 * time.Second
+
+// trackConn records an open WebSocket so shutdown can close it. It returns
+// false once shutdown has started, in which case the caller should hang up.
+func (s *Server) trackConn(conn *websocket.Conn) bool {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackConn(conn *websocket.Conn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	delete(s.conns, conn)
+	if s.drained != nil && len(s.conns) == 0 {
+		close(s.drained)
+		s.drained = nil
+	}
+}
+
+// closeWebSockets sends a going-away close frame to every open WebSocket and
+// waits up to timeout for their handlers to finish before dropping the rest
+func (s *Server) closeWebSockets(timeout time.Duration) {
+	s.connMu.Lock()
+	s.closing = true
+	conns := make([]*websocket.Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	if len(conns) == 0 {
+		s.connMu.Unlock()
+		return
+	}
+	drained := make(chan struct{})
+	s.drained = drained
+	s.connMu.Unlock()
+
+	// WriteControl is safe to call alongside the handler's own writes
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(s.writeWait)
+	for _, conn := range conns {
+		if err := conn.WriteControl(websocket.CloseMessage, message, deadline); err != nil {
+			s.logger.V(1).Info("WebSocket close frame failed", "remote", conn.RemoteAddr().String(), "error", err.Error())
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return
+	case <-timer.C:
+	}
+
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
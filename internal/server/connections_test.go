@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestServer_CloseWebSockets(t *testing.T) {
+	s := NewServer(&fakeProvider{}, "", 0)
+	httpServer := httptest.NewServer(s.Handler())
+	defer httpServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		s.closeWebSockets(2 * time.Second)
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("ReadMessage() error = %v, want close frame", err)
+	}
+	if closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("close code = %v, want %v", closeErr.Code, websocket.CloseGoingAway)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("closeWebSockets() did not return after the client answered the close")
+	}
+
+	s.connMu.Lock()
+	open := len(s.conns)
+	s.connMu.Unlock()
+	if open != 0 {
+		t.Errorf("open connections after shutdown = %v, want 0", open)
+	}
+
+	late, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer late.Close()
+	late.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := late.ReadMessage(); err == nil {
+		t.Errorf("ReadMessage() on a connection opened during shutdown succeeded, want it hung up")
+	}
+}
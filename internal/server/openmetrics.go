@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+type openMetricsFamily struct {
+	name  string
+	help  string
+	unit  string
+	value func(*types.ServiceHealthInfo) float64
+}
+
+var healthMetricFamilies = []openMetricsFamily{
+	{
+		name: "constellation_service_up",
+		help: "Whether the most recent check of the service succeeded.",
+		value: func(info *types.ServiceHealthInfo) float64 {
+			if info.Status == types.HealthStatusHealthy {
+				return 1
+			}
+			return 0
+		},
+	},
+	{
+		name: "constellation_service_latency_seconds",
+		help: "Latency of the most recent check of the service.",
+		unit: "seconds",
+		value: func(info *types.ServiceHealthInfo) float64 {
+			return info.History[len(info.History)-1].Latency.Seconds()
+		},
+	},
+	{
+		name: "constellation_service_uptime_ratio",
+		help: "Fraction of recent checks of the service that succeeded.",
+		unit: "ratio",
+		value: func(info *types.ServiceHealthInfo) float64 {
+			return info.Uptime / 100
+		},
+	},
+}
+
+func (s *Server) handleHealthMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", openMetricsContentType)
+	if err := writeOpenMetrics(w, s.healthProvider.GetAllHealthData()); err != nil {
+		s.logger.Error(err, "failed to write health metrics")
+	}
+}
+
+// writeOpenMetrics renders per-service health in the OpenMetrics text format.
+// Samples carry the time of the check that produced them, and services that
+// have not been checked yet are omitted.
+func writeOpenMetrics(w io.Writer, data []*types.ServiceHealthInfo) error {
+	var checked []*types.ServiceHealthInfo
+	for _, info := range data {
+		if len(info.History) > 0 {
+			checked = append(checked, info)
+		}
+	}
+
+	var b strings.Builder
+	for _, family := range healthMetricFamilies {
+		b.WriteString("# TYPE " + family.name + " gauge\n")
+		if family.unit != "" {
+			b.WriteString("# UNIT " + family.name + " " + family.unit + "\n")
+		}
+		b.WriteString("# HELP " + family.name + " " + family.help + "\n")
+
+		for _, info := range checked {
+			b.WriteString(family.name)
+			b.WriteString(`{namespace="` + escapeLabelValue(info.Namespace) + `",service="` +
+				escapeLabelValue(info.ServiceName) + `"} `)
+			b.WriteString(strconv.FormatFloat(family.value(info), 'g', -1, 64))
+			b.WriteString(" ")
+			b.WriteString(strconv.FormatFloat(float64(info.LastCheck.UnixMilli())/1000, 'f', 3, 64))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	checkedAt := time.Date(2025, 1, 2, 3, 4, 5, 250_000_000, time.UTC)
+
+	tests := []struct {
+		name string
+		data []*types.ServiceHealthInfo
+		want string
+	}{
+		{
+			name: "no services",
+			want: `# TYPE constellation_service_up gauge
+# HELP constellation_service_up Whether the most recent check of the service succeeded.
+# TYPE constellation_service_latency_seconds gauge
+# UNIT constellation_service_latency_seconds seconds
+# HELP constellation_service_latency_seconds Latency of the most recent check of the service.
+# TYPE constellation_service_uptime_ratio gauge
+# UNIT constellation_service_uptime_ratio ratio
+# HELP constellation_service_uptime_ratio Fraction of recent checks of the service that succeeded.
+# EOF
+`,
+		},
+		{
+			name: "checked services with escaped labels",
+			data: []*types.ServiceHealthInfo{
+				{
+					Namespace:   "default",
+					ServiceName: "api",
+					Status:      types.HealthStatusHealthy,
+					LastCheck:   checkedAt,
+					Uptime:      100,
+					History:     []types.HealthCheckEntry{{Latency: 20 * time.Millisecond}},
+				},
+				{
+					Namespace:   "team\"a",
+					ServiceName: "worker",
+					Status:      types.HealthStatusUnhealthy,
+					LastCheck:   checkedAt,
+					Uptime:      50,
+					History:     []types.HealthCheckEntry{{Latency: time.Second}, {Latency: 1500 * time.Millisecond}},
+				},
+				{
+					Namespace:   "default",
+					ServiceName: "unchecked",
+					Status:      types.HealthStatusUnknown,
+				},
+			},
+			want: `# TYPE constellation_service_up gauge
+# HELP constellation_service_up Whether the most recent check of the service succeeded.
+constellation_service_up{namespace="default",service="api"} 1 1735787045.250
+constellation_service_up{namespace="team\"a",service="worker"} 0 1735787045.250
+# TYPE constellation_service_latency_seconds gauge
+# UNIT constellation_service_latency_seconds seconds
+# HELP constellation_service_latency_seconds Latency of the most recent check of the service.
+constellation_service_latency_seconds{namespace="default",service="api"} 0.02 1735787045.250
+constellation_service_latency_seconds{namespace="team\"a",service="worker"} 1.5 1735787045.250
+# TYPE constellation_service_uptime_ratio gauge
+# UNIT constellation_service_uptime_ratio ratio
+# HELP constellation_service_uptime_ratio Fraction of recent checks of the service that succeeded.
+constellation_service_uptime_ratio{namespace="default",service="api"} 1 1735787045.250
+constellation_service_uptime_ratio{namespace="team\"a",service="worker"} 0.5 1735787045.250
+# EOF
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeOpenMetrics(&b, tt.data); err != nil {
+				t.Fatalf("writeOpenMetrics() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeOpenMetrics() =\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_HandleHealthMetrics(t *testing.T) {
+	s := NewServer(&fakeProvider{}, "", 0)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthmetrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /healthmetrics status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != openMetricsContentType {
+		t.Errorf("GET /healthmetrics content type = %v, want %v", got, openMetricsContentType)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "# EOF\n") {
+		t.Errorf("GET /healthmetrics body does not end with # EOF: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithAllowedOrigins sets the origins, besides the server's own, that may open a
+// WebSocket. Entries are either an origin ("https://app.example.com") or a host
+// pattern ("app.example.com", "*.example.com") matching any scheme. "*" allows
+// every origin.
+func WithAllowedOrigins(origins []string) ServerOpt {
+	return func(s *Server) {
+		s.allowedOrigins = nil
+		for _, origin := range origins {
+			origin = strings.TrimSpace(origin)
+			if origin == "" {
+				continue
+			}
+			s.allowedOrigins = append(s.allowedOrigins, strings.ToLower(origin))
+		}
+	}
+}
+
+// checkOrigin allows same-origin requests, requests from allowlisted origins,
+// and requests without an Origin header, which browsers always send
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range s.allowedOrigins {
+		if originMatches(allowed, u) {
+			return true
+		}
+	}
+	return false
+}
+
+func originMatches(allowed string, origin *url.URL) bool {
+	if allowed == "*" {
+		return true
+	}
+
+	pattern := allowed
+	if scheme, host, found := strings.Cut(allowed, "://"); found {
+		if !strings.EqualFold(scheme, origin.Scheme) {
+			return false
+		}
+		pattern = host
+	}
+
+	host := strings.ToLower(origin.Hostname())
+	if _, _, err := net.SplitHostPort(pattern); err == nil {
+		host = strings.ToLower(origin.Host)
+	}
+
+	suffix, wildcard := strings.CutPrefix(pattern, "*.")
+	if !wildcard {
+		return host == pattern
+	}
+	return strings.HasSuffix(host, "."+suffix)
+}
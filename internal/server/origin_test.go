@@ -0,0 +1,154 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestServer_CheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{
+			name:   "no origin header",
+			host:   "constellation.local",
+			origin: "",
+			want:   true,
+		},
+		{
+			name:   "same origin",
+			host:   "constellation.local:8080",
+			origin: "http://constellation.local:8080",
+			want:   true,
+		},
+		{
+			name:   "cross origin rejected by default",
+			host:   "constellation.local",
+			origin: "https://evil.example.com",
+			want:   false,
+		},
+		{
+			name:    "exact origin match",
+			allowed: []string{"https://app.example.com"},
+			host:    "constellation.local",
+			origin:  "https://app.example.com",
+			want:    true,
+		},
+		{
+			name:    "exact origin with different scheme",
+			allowed: []string{"https://app.example.com"},
+			host:    "constellation.local",
+			origin:  "http://app.example.com",
+			want:    false,
+		},
+		{
+			name:    "host match ignores case",
+			allowed: []string{"App.Example.com"},
+			host:    "constellation.local",
+			origin:  "https://app.example.COM",
+			want:    true,
+		},
+		{
+			name:    "wildcard subdomain",
+			allowed: []string{"*.example.com"},
+			host:    "constellation.local",
+			origin:  "https://dash.team.example.com",
+			want:    true,
+		},
+		{
+			name:    "wildcard does not match apex",
+			allowed: []string{"*.example.com"},
+			host:    "constellation.local",
+			origin:  "https://example.com",
+			want:    false,
+		},
+		{
+			name:    "wildcard does not match suffix lookalike",
+			allowed: []string{"*.example.com"},
+			host:    "constellation.local",
+			origin:  "https://evilexample.com",
+			want:    false,
+		},
+		{
+			name:    "host pattern with port",
+			allowed: []string{"app.example.com:8443"},
+			host:    "constellation.local",
+			origin:  "https://app.example.com:9443",
+			want:    false,
+		},
+		{
+			name:    "allow all",
+			allowed: []string{"*"},
+			host:    "constellation.local",
+			origin:  "https://anything.test",
+			want:    true,
+		},
+		{
+			name:    "malformed origin",
+			allowed: []string{"*.example.com"},
+			host:    "constellation.local",
+			origin:  "://bad",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, "", 0, WithAllowedOrigins(tt.allowed))
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			if got := s.checkOrigin(req); got != tt.want {
+				t.Errorf("checkOrigin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_WebSocketRejectsDisallowedOrigin(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		wantStatus int
+	}{
+		{
+			name:       "allowed origin upgrades",
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "disallowed origin is forbidden",
+			origin:     "https://evil.example.org",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, "", 0, WithAllowedOrigins([]string{"*.example.com"}))
+			httpServer := httptest.NewServer(s.Handler())
+			defer httpServer.Close()
+
+			wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+			conn, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{tt.origin}})
+			if conn != nil {
+				defer conn.Close()
+			}
+			if resp == nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Dial() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
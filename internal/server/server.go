@@ -2,31 +2,49 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/gorilla/websocket"
+	"github.com/kdwils/constellation/internal/healthcheck"
+	"github.com/kdwils/constellation/internal/hierarchy"
+	"github.com/kdwils/constellation/internal/jsonschema"
 	"github.com/kdwils/constellation/internal/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
-	writeWait      = 10 * time.Second
-	pongWait       = 60 * time.Second
-	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	defaultWriteWait      = 10 * time.Second
+	defaultPongWait       = 60 * time.Second
+	defaultMaxMessageSize = 512
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-	HandshakeTimeout: 5 * time.Second,
-}
-
 type HealthDataProvider interface {
 	GetAllHealthData() []*types.ServiceHealthInfo
+	GetAllHealthDataSorted(order healthcheck.SortOrder) []*types.ServiceHealthInfo
+	GetHealthDataPage(offset, limit int) ([]*types.ServiceHealthInfo, int)
+	GetHealthSummary() types.HealthSummary
+	GetGroupHealth(group string) (types.GroupHealth, bool)
+	OverrideTarget(name string, interval, timeout time.Duration) error
+	RunCheckNow(ctx context.Context, name string) ([]types.HealthCheckEntry, error)
+	RunNamespaceChecksNow(ctx context.Context, namespace string) (types.NamespaceChecks, error)
+	GetEvents(since time.Time) []types.StateEvent
+	GetTargets() []healthcheck.HealthTarget
 	Subscribe() chan []*types.ServiceHealthInfo
 	Unsubscribe(chan []*types.ServiceHealthInfo)
 }
@@ -35,36 +53,176 @@ type Server struct {
 	healthProvider HealthDataProvider
 	staticDir      string
 	port           int
+	adminToken     string
+	allowedOrigins []string
+	logger         logr.Logger
+	upgrader       websocket.Upgrader
+	readiness      []readinessCheck
+	assetMaxAge    time.Duration
+	version        string
+	bundleHash     atomic.Value
+
+	writeWait      time.Duration
+	pongWait       time.Duration
+	pingPeriod     time.Duration
+	maxMessageSize int64
+
+	// done is closed when shutdown starts so streaming handlers return
+	done    chan struct{}
+	connMu  sync.Mutex
+	conns   map[*websocket.Conn]struct{}
+	closing bool
+	drained chan struct{}
+}
+
+type ServerOpt func(*Server)
+
+type readinessCheck struct {
+	name  string
+	ready func() bool
+}
+
+// WithAdminToken enables the admin endpoints, which require this bearer token
+func WithAdminToken(token string) ServerOpt {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithReadiness adds a named predicate that /readyz and /healthz consult,
+// e.g. whether the controller caches have synced. They report 503 until every
+// predicate returns true. Without any the server is always ready.
+func WithReadiness(name string, ready func() bool) ServerOpt {
+	return func(s *Server) {
+		s.readiness = append(s.readiness, readinessCheck{name: name, ready: ready})
+	}
+}
+
+// WithWebSocketTimeouts sets how long a write may take, how long a client may
+// go without answering a ping, and how often pings are sent. Pings must be sent
+// more often than pongWait, so a pingPeriod that is not shorter falls back to
+// 9/10 of pongWait. Zero keeps the default for that value.
+func WithWebSocketTimeouts(writeWait, pongWait, pingPeriod time.Duration) ServerOpt {
+	return func(s *Server) {
+		if writeWait > 0 {
+			s.writeWait = writeWait
+		}
+		if pongWait > 0 {
+			s.pongWait = pongWait
+		}
+		s.pingPeriod = pingPeriodFor(s.pongWait)
+		if pingPeriod > 0 && pingPeriod < s.pongWait {
+			s.pingPeriod = pingPeriod
+		}
+	}
+}
+
+// WithWebSocketReadLimit sets the largest message in bytes a client may send
+// before its connection is closed. Zero keeps the default.
+func WithWebSocketReadLimit(limit int64) ServerOpt {
+	return func(s *Server) {
+		if limit > 0 {
+			s.maxMessageSize = limit
+		}
+	}
+}
+
+func pingPeriodFor(pongWait time.Duration) time.Duration {
+	return (pongWait * 9) / 10
+}
+
+// WithLogger sets the logger used for connection lifecycle and errors
+func WithLogger(logger logr.Logger) ServerOpt {
+	return func(s *Server) {
+		s.logger = logger
+	}
 }
 
-func NewServer(healthProvider HealthDataProvider, staticDir string, port int) *Server {
-	return &Server{
+func NewServer(healthProvider HealthDataProvider, staticDir string, port int, opts ...ServerOpt) *Server {
+	s := &Server{
 		healthProvider: healthProvider,
 		staticDir:      staticDir,
 		port:           port,
+		logger:         log.Log.WithName("server"),
+		assetMaxAge:    defaultAssetMaxAge,
+		writeWait:      defaultWriteWait,
+		pongWait:       defaultPongWait,
+		pingPeriod:     pingPeriodFor(defaultPongWait),
+		maxMessageSize: defaultMaxMessageSize,
+		done:           make(chan struct{}),
+		conns:          make(map[*websocket.Conn]struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.upgrader = websocket.Upgrader{
+		CheckOrigin:      s.checkOrigin,
+		HandshakeTimeout: 5 * time.Second,
+	}
+
+	// Serving the API without a built frontend is normal during development
+	if err := s.Rehash(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Error(err, "failed to hash frontend bundle")
+	}
+
+	return s
 }
 
-func (s *Server) Serve(ctx context.Context) error {
+// Handler returns the HTTP handler serving all server routes
+func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/state", s.handleState)
+	mux.HandleFunc("GET /summary", s.handleSummary)
+	mux.HandleFunc("GET /groups/{group}/health", s.handleGroupHealth)
+	mux.HandleFunc("GET /healthmetrics", s.handleHealthMetrics)
+	mux.HandleFunc("GET /targets", s.handleTargets)
+	mux.HandleFunc("GET /cluster-state", s.handleClusterState)
+	mux.HandleFunc("GET /schema", s.handleSchema)
+	mux.HandleFunc("GET /version", s.handleVersion)
 	mux.HandleFunc("/ws", s.handleWebSocket)
-	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/ws/connections", s.handleConnectionsWebSocket)
+	mux.HandleFunc("/ws/hierarchy", s.handleHierarchyWebSocket)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /events/log", s.handleEventLog)
+	mux.HandleFunc("/healthz", s.handleReady)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/livez", s.handleLive)
+	mux.HandleFunc("PATCH /healthchecks/{namespace}/{service}", s.requireAdmin(s.handleOverrideTarget))
+	mux.HandleFunc("POST /health/{namespace}/{service}/check", s.requireAdmin(s.handleRunCheck))
+	mux.HandleFunc("POST /health/{namespace}/check", s.requireAdmin(s.handleRunNamespaceChecks))
 
 	if s.staticDir != "" {
 		fileServer := http.FileServer(http.Dir(s.staticDir))
 		mux.Handle("/", s.staticFileHandler(fileServer))
 	}
 
+	return mux
+}
+
+func (s *Server) Serve(ctx context.Context) error {
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+		Handler: s.Handler(),
 	}
 
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+	go s.reloadOnSignal(ctx, hangups)
+
 	go func() {
 		<-ctx.Done()
-		httpServer.Shutdown(context.Background())
+		// Hijacked WebSocket connections are not tracked by Shutdown, so they
+		// get their close handshake first
+		close(s.done)
+		s.closeWebSockets(shutdownTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
 	}()
 
 	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -73,70 +231,504 @@ func (s *Server) Serve(ctx context.Context) error {
 	return nil
 }
 
+// handleState returns every service as a JSON array. Passing offset or limit
+// switches to a paginated HealthDataPage envelope instead. A comma-separated
+// ?status= keeps only services with one of those statuses.
 func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
-	healthData := s.healthProvider.GetAllHealthData()
+	query := r.URL.Query()
+	statuses, err := parseStatuses(query.Get("status"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %v", err))
+		return
+	}
+	order, err := healthcheck.ParseSortOrder(query.Get("sort"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid sort: %v", err))
+		return
+	}
+	if query.Has("offset") || query.Has("limit") {
+		s.handleStatePage(w, query.Get("offset"), query.Get("limit"), order, statuses)
+		return
+	}
+	if query.Has("groupBy") {
+		// The hierarchy orders its nodes by name, so a sort order cannot apply
+		if query.Get("sort") != "" {
+			writeJSONError(w, http.StatusBadRequest, "sort cannot be combined with groupBy")
+			return
+		}
+		s.handleGroupedState(w, query.Get("groupBy"), statuses)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(healthData); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	healthData := filterByStatus(s.healthProvider.GetAllHealthDataSorted(order), statuses)
+
+	writeJSON(w, http.StatusOK, healthData)
+}
+
+func (s *Server) handleStatePage(
+	w http.ResponseWriter,
+	rawOffset, rawLimit string,
+	order healthcheck.SortOrder,
+	statuses []types.HealthStatus,
+) {
+	offset, err := parseNonNegativeInt(rawOffset)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid offset: %v", err))
+		return
+	}
+	limit, err := parseNonNegativeInt(rawLimit)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %v", err))
+		return
+	}
+
+	items, total := s.statePage(offset, limit, order, statuses)
+	page := types.HealthDataPage{
+		Items:  items,
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// statePage pages the filtered services. The provider can only page the
+// unfiltered set in key order, so other pages are cut from the full list here.
+func (s *Server) statePage(
+	offset, limit int,
+	order healthcheck.SortOrder,
+	statuses []types.HealthStatus,
+) ([]*types.ServiceHealthInfo, int) {
+	if len(statuses) == 0 && order == healthcheck.SortByKey {
+		return s.healthProvider.GetHealthDataPage(offset, limit)
+	}
+
+	filtered := filterByStatus(s.healthProvider.GetAllHealthDataSorted(order), statuses)
+	total := len(filtered)
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+	return filtered[start:end], total
+}
+
+// handleGroupedState returns the hierarchy with services nested under the
+// groups assigned by their group annotation
+func (s *Server) handleGroupedState(w http.ResponseWriter, groupBy string, statuses []types.HealthStatus) {
+	if groupBy != "annotation" {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported groupBy %q, must be annotation", groupBy))
+		return
+	}
+
+	nodes := hierarchy.BuildGrouped(filterByStatus(s.healthProvider.GetAllHealthData(), statuses))
+
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+// parseStatuses parses a comma-separated list of health statuses. An empty
+// list matches every status.
+func parseStatuses(value string) ([]types.HealthStatus, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var statuses []types.HealthStatus
+	for _, raw := range strings.Split(value, ",") {
+		status := types.HealthStatus(strings.TrimSpace(raw))
+		switch status {
+		case types.HealthStatusHealthy, types.HealthStatusUnhealthy, types.HealthStatusUnknown,
+			types.HealthStatusMaintenance:
+			statuses = append(statuses, status)
+		default:
+			return nil, fmt.Errorf("unknown status %q", raw)
+		}
+	}
+	return statuses, nil
+}
+
+// filterByStatus keeps the services whose status is one of statuses, or all
+// of them when statuses is empty
+func filterByStatus(infos []*types.ServiceHealthInfo, statuses []types.HealthStatus) []*types.ServiceHealthInfo {
+	if len(statuses) == 0 {
+		return infos
+	}
+
+	filtered := make([]*types.ServiceHealthInfo, 0, len(infos))
+	for _, info := range infos {
+		if slices.Contains(statuses, info.Status) {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// handleClusterState returns the grouped hierarchy flattened into resources
+// keyed by node ID and the edges between them
+func (s *Server) handleClusterState(w http.ResponseWriter, r *http.Request) {
+	state := hierarchy.BuildClusterState(hierarchy.BuildGrouped(s.healthProvider.GetAllHealthData()))
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleSchema returns a JSON Schema for the documents served by /state in
+// both its flat and grouped forms
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	schema := jsonschema.Document(
+		reflect.TypeFor[types.ServiceHealthInfo](),
+		reflect.TypeFor[types.HierarchyNode](),
+	)
+
+	writeJSONAs(w, http.StatusOK, "application/schema+json", schema)
+}
+
+func parseNonNegativeInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("must not be negative, got %d", n)
+	}
+	return n, nil
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	summary := s.healthProvider.GetHealthSummary()
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) handleGroupHealth(w http.ResponseWriter, r *http.Request) {
+	group := r.PathValue("group")
+	health, exists := s.healthProvider.GetGroupHealth(group)
+	if !exists {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("group %q not found", group))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, health)
+}
+
+// requireAdmin rejects requests without the configured admin bearer token. Admin
+// endpoints are disabled entirely when no token is configured.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			writeJSONError(w, http.StatusForbidden, "admin API is disabled")
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+type targetOverride struct {
+	Interval string `json:"interval,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`
+}
+
+func (s *Server) handleOverrideTarget(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("namespace") + "/" + r.PathValue("service")
+
+	var override targetOverride
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	interval, err := parseOptionalDuration(override.Interval)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid interval: %v", err))
+		return
+	}
+	timeout, err := parseOptionalDuration(override.Timeout)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid timeout: %v", err))
+		return
+	}
+	if interval == 0 && timeout == 0 {
+		writeJSONError(w, http.StatusBadRequest, "interval or timeout is required")
+		return
+	}
+
+	err = s.healthProvider.OverrideTarget(name, interval, timeout)
+	if errors.Is(err, healthcheck.ErrTargetNotFound) {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "health check updated",
+	})
+}
+
+func (s *Server) handleRunCheck(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("namespace") + "/" + r.PathValue("service")
+
+	entries, err := s.healthProvider.RunCheckNow(r.Context(), name)
+	if errors.Is(err, healthcheck.ErrTargetNotFound) {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, healthcheck.ErrRateLimited) {
+		writeJSONError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleRunNamespaceChecks checks every target in a namespace at once, e.g.
+// after a deploy, and returns the entries of each service and the services
+// skipped by the manual check limit
+func (s *Server) handleRunNamespaceChecks(w http.ResponseWriter, r *http.Request) {
+	results, err := s.healthProvider.RunNamespaceChecksNow(r.Context(), r.PathValue("namespace"))
+	if errors.Is(err, healthcheck.ErrTargetNotFound) {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, healthcheck.ErrRateLimited) {
+		writeJSONError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleTargets lists every registered target with the checks polled for it
+func (s *Server) handleTargets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.healthProvider.GetTargets())
+}
+
+// handleEventLog returns recent target changes, optionally only those after
+// the RFC 3339 timestamp in ?since=
+func (s *Server) handleEventLog(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid since: %v", err))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.healthProvider.GetEvents(since))
+}
+
+// writeJSON encodes v before writing any headers so an encoding failure can
+// still be reported as a 500 rather than a truncated 200
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	writeJSONAs(w, status, "application/json", v)
+}
+
+// writeJSONAs is writeJSON for JSON served under a more specific media type
+func writeJSONAs(w http.ResponseWriter, status int, contentType string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
+
+// writeJSONError responds with {"error": msg} so every handler fails with the
+// same shape
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	body, _ := json.Marshal(map[string]string{"error": msg})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
+
+func parseOptionalDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", value)
+	}
+	return d, nil
 }
 
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	s.streamWebSocket(w, r, func(data []*types.ServiceHealthInfo) any { return data })
+}
+
+// handleConnectionsWebSocket streams the edges of the grouped hierarchy,
+// sending only when they change, so graph views do not have to consume the
+// full health payload
+func (s *Server) handleConnectionsWebSocket(w http.ResponseWriter, r *http.Request) {
+	var last []types.Connection
+	s.streamWebSocket(w, r, func(data []*types.ServiceHealthInfo) any {
+		connections := hierarchy.BuildConnections(hierarchy.BuildGrouped(data))
+		if last != nil && slices.Equal(last, connections) {
+			return nil
+		}
+		last = connections
+		return connections
+	})
+}
+
+// hierarchyMessage is sent on /ws/hierarchy. The first message of a
+// connection is a snapshot with seq 0. Every later message carries the ops
+// that turn the previous tree into the current one and the next seq, so a
+// client that sees a gap reconnects to resync from a new snapshot.
+type hierarchyMessage struct {
+	Type  string                `json:"type"`
+	Seq   uint64                `json:"seq"`
+	Nodes []types.HierarchyNode `json:"nodes,omitempty"`
+	Ops   []hierarchy.Op        `json:"ops,omitempty"`
+}
+
+const (
+	hierarchyMessageSnapshot = "snapshot"
+	hierarchyMessageOps      = "ops"
+)
+
+// handleHierarchyWebSocket streams the grouped hierarchy as a snapshot followed
+// by per-node ops, so clients can update their tree in place
+func (s *Server) handleHierarchyWebSocket(w http.ResponseWriter, r *http.Request) {
+	var last []types.HierarchyNode
+	var seq uint64
+	sent := false
+	s.streamWebSocket(w, r, func(data []*types.ServiceHealthInfo) any {
+		nodes := hierarchy.BuildGrouped(data)
+		if !sent {
+			sent = true
+			last = nodes
+			return hierarchyMessage{Type: hierarchyMessageSnapshot, Seq: seq, Nodes: nodes}
+		}
+
+		ops := hierarchy.Diff(last, nodes)
+		if len(ops) == 0 {
+			return nil
+		}
+		last = nodes
+		seq++
+		return hierarchyMessage{Type: hierarchyMessageOps, Seq: seq, Ops: ops}
+	})
+}
+
+// streamWebSocket sends payload of the current health data on connect and
+// after every update. Updates for which payload returns nil are skipped.
+func (s *Server) streamWebSocket(
+	w http.ResponseWriter,
+	r *http.Request,
+	payload func([]*types.ServiceHealthInfo) any,
+) {
+	// Upgrade writes the error response itself, e.g. 403 for a disallowed origin
+	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("WebSocket upgrade error: %v", err), http.StatusBadRequest)
+		s.logger.V(1).Info("WebSocket upgrade failed", "remote", r.RemoteAddr, "origin", r.Header.Get("Origin"),
+			"error", err.Error())
+		return
+	}
+
+	logger := s.logger.WithValues("remote", r.RemoteAddr)
+	if !s.trackConn(conn) {
+		conn.Close()
 		return
 	}
 	defer func() {
-		fmt.Printf("WebSocket connection closed\n")
+		logger.V(1).Info("WebSocket connection closed")
 		conn.Close()
+		s.untrackConn(conn)
 	}()
 
-	fmt.Printf("WebSocket connection established\n")
+	logger.V(1).Info("WebSocket connection established")
 
-	conn.SetReadLimit(maxMessageSize)
-	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetReadLimit(s.maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(s.pongWait))
 	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetReadDeadline(time.Now().Add(s.pongWait))
 		return nil
 	})
 
 	healthChan := s.healthProvider.Subscribe()
 	defer s.healthProvider.Unsubscribe(healthChan)
 
-	if err := s.writeMessage(conn, s.healthProvider.GetAllHealthData()); err != nil {
-		fmt.Printf("WebSocket initial write error: %v\n", err)
+	if err := s.writeMessage(conn, payload(s.healthProvider.GetAllHealthData())); err != nil {
+		logger.Error(err, "WebSocket initial write error")
 		return
 	}
 
+	// The read loop is the only place a client disconnect is observed, so it
+	// signals the write loop to stop instead of waiting for the next ping to fail
+	readDone := make(chan struct{})
 	go func() {
+		defer close(readDone)
 		for {
 			_, _, err := conn.ReadMessage()
-			if err != nil {
-				fmt.Printf("WebSocket read error: %v\n", err)
+			if err == nil {
+				continue
+			}
+			// Clients closing the page is routine and only worth noting at debug level
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.V(1).Info("WebSocket closed by client")
 				return
 			}
+			logger.Error(err, "WebSocket read error")
+			return
 		}
 	}()
 
-	pingTicker := time.NewTicker(pingPeriod)
+	pingTicker := time.NewTicker(s.pingPeriod)
 	defer pingTicker.Stop()
 
 	for {
 		select {
 		case data := <-healthChan:
-			if err := s.writeMessage(conn, data); err != nil {
-				fmt.Printf("WebSocket write error: %v\n", err)
+			message := payload(data)
+			if message == nil {
+				continue
+			}
+			if err := s.writeMessage(conn, message); err != nil {
+				logger.Error(err, "WebSocket write error")
 				return
 			}
 		case <-pingTicker.C:
-			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			conn.SetWriteDeadline(time.Now().Add(s.writeWait))
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				fmt.Printf("WebSocket ping error: %v\n", err)
+				logger.Error(err, "WebSocket ping error")
 				return
 			}
+		case <-readDone:
+			return
 		case <-r.Context().Done():
 			return
 		}
@@ -144,30 +736,37 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) writeMessage(conn *websocket.Conn, data any) error {
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.SetWriteDeadline(time.Now().Add(s.writeWait))
 	return conn.WriteJSON(data)
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	healthData := s.healthProvider.GetAllHealthData()
-	ready := len(healthData) > 0
+// handleLive reports that the process is up and serving requests
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "alive",
+	})
+}
+
+// handleReady reports whether every readiness predicate holds
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	// Zero health-checked services is a valid state, so readiness only
+	// depends on the subsystems having started
+	var waiting []string
+	for _, check := range s.readiness {
+		if !check.ready() {
+			waiting = append(waiting, check.name)
+		}
+	}
 
-	if !ready {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "waiting for kubernetes resources",
+	if len(waiting) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"message": "not ready",
+			"waiting": waiting,
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	writeJSON(w, http.StatusOK, map[string]string{
 		"message": "ready",
 	})
 }
-
-func (s *Server) staticFileHandler(fileServer http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		fileServer.ServeHTTP(w, r)
-	}
-}
@@ -0,0 +1,1359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/gorilla/websocket"
+
+	"github.com/kdwils/constellation/internal/healthcheck"
+	"github.com/kdwils/constellation/internal/hierarchy"
+	"github.com/kdwils/constellation/internal/types"
+)
+
+type fakeProvider struct {
+	targets  map[string]bool
+	limited  map[string]bool
+	groups   map[string]types.GroupHealth
+	name     string
+	interval time.Duration
+	timeout  time.Duration
+	entries  []types.HealthCheckEntry
+	updates  chan []*types.ServiceHealthInfo
+	data     []*types.ServiceHealthInfo
+	events   []types.StateEvent
+	checks   []healthcheck.HealthTarget
+}
+
+func (f *fakeProvider) GetAllHealthData() []*types.ServiceHealthInfo { return f.data }
+
+func (f *fakeProvider) GetAllHealthDataSorted(order healthcheck.SortOrder) []*types.ServiceHealthInfo {
+	data := slices.Clone(f.data)
+	healthcheck.SortHealthData(data, order)
+	return data
+}
+
+func (f *fakeProvider) GetHealthDataPage(offset, limit int) ([]*types.ServiceHealthInfo, int) {
+	start := min(offset, len(f.data))
+	end := len(f.data)
+	if limit > 0 {
+		end = min(start+limit, len(f.data))
+	}
+	return f.data[start:end], len(f.data)
+}
+
+func (f *fakeProvider) GetEvents(since time.Time) []types.StateEvent {
+	events := []types.StateEvent{}
+	for _, event := range f.events {
+		if event.Timestamp.After(since) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (f *fakeProvider) GetTargets() []healthcheck.HealthTarget { return f.checks }
+
+func (f *fakeProvider) GetHealthSummary() types.HealthSummary { return types.HealthSummary{} }
+
+func (f *fakeProvider) GetGroupHealth(group string) (types.GroupHealth, bool) {
+	health, exists := f.groups[group]
+	return health, exists
+}
+
+func (f *fakeProvider) Subscribe() chan []*types.ServiceHealthInfo {
+	if f.updates != nil {
+		return f.updates
+	}
+	return make(chan []*types.ServiceHealthInfo)
+}
+
+func (f *fakeProvider) Unsubscribe(chan []*types.ServiceHealthInfo) {}
+
+func (f *fakeProvider) RunCheckNow(_ context.Context, name string) ([]types.HealthCheckEntry, error) {
+	if !f.targets[name] {
+		return nil, healthcheck.ErrTargetNotFound
+	}
+	if f.limited[name] {
+		return nil, healthcheck.ErrRateLimited
+	}
+	return f.entries, nil
+}
+
+func (f *fakeProvider) RunNamespaceChecksNow(_ context.Context, namespace string) (types.NamespaceChecks, error) {
+	results := types.NamespaceChecks{Services: make(map[string][]types.HealthCheckEntry)}
+	for name := range f.targets {
+		targetNamespace, service, _ := strings.Cut(name, "/")
+		if targetNamespace != namespace {
+			continue
+		}
+		if f.limited[name] {
+			results.RateLimited = append(results.RateLimited, service)
+			continue
+		}
+		results.Services[service] = f.entries
+	}
+	slices.Sort(results.RateLimited)
+	if len(results.Services) == 0 && len(results.RateLimited) > 0 {
+		return types.NamespaceChecks{}, healthcheck.ErrRateLimited
+	}
+	if len(results.Services) == 0 {
+		return types.NamespaceChecks{}, healthcheck.ErrTargetNotFound
+	}
+	return results, nil
+}
+
+func (f *fakeProvider) OverrideTarget(name string, interval, timeout time.Duration) error {
+	if !f.targets[name] {
+		return healthcheck.ErrTargetNotFound
+	}
+	f.name = name
+	f.interval = interval
+	f.timeout = timeout
+	return nil
+}
+
+func TestServer_HandleOverrideTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		adminToken   string
+		authHeader   string
+		path         string
+		body         string
+		wantStatus   int
+		wantInterval time.Duration
+		wantTimeout  time.Duration
+	}{
+		{
+			name:       "admin API disabled without token",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"30s"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing bearer token",
+			adminToken: "secret",
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"30s"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong bearer token",
+			adminToken: "secret",
+			authHeader: "Bearer wrong",
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"30s"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid body",
+			adminToken: "secret",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/api",
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid interval",
+			adminToken: "secret",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"soon"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "non-positive timeout",
+			adminToken: "secret",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/api",
+			body:       `{"timeout":"-1s"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no fields",
+			adminToken: "secret",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/api",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown target",
+			adminToken: "secret",
+			authHeader: "Bearer secret",
+			path:       "/healthchecks/default/missing",
+			body:       `{"interval":"30s"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:         "overrides interval and timeout",
+			adminToken:   "secret",
+			authHeader:   "Bearer secret",
+			path:         "/healthchecks/default/api",
+			body:         `{"interval":"30s","timeout":"5s"}`,
+			wantStatus:   http.StatusAccepted,
+			wantInterval: 30 * time.Second,
+			wantTimeout:  5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{targets: map[string]bool{"default/api": true}}
+			s := NewServer(provider, "", 0, WithAdminToken(tt.adminToken))
+
+			req := httptest.NewRequest(http.MethodPatch, tt.path, strings.NewReader(tt.body))
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("PATCH %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+			if provider.name != "default/api" {
+				t.Errorf("OverrideTarget() name = %v, want %v", provider.name, "default/api")
+			}
+			if provider.interval != tt.wantInterval {
+				t.Errorf("OverrideTarget() interval = %v, want %v", provider.interval, tt.wantInterval)
+			}
+			if provider.timeout != tt.wantTimeout {
+				t.Errorf("OverrideTarget() timeout = %v, want %v", provider.timeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+type logCapture struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *logCapture) write(_, args string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, args)
+}
+
+func (l *logCapture) contains(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServer_WebSocketLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		closeCode int
+		wantLogs  []string
+		wantNot   []string
+	}{
+		{
+			name:      "unexpected close is logged as an error",
+			closeCode: websocket.CloseProtocolError,
+			wantLogs: []string{
+				`"msg"="WebSocket connection established"`,
+				`"msg"="WebSocket read error"`,
+				`"msg"="WebSocket connection closed"`,
+			},
+		},
+		{
+			name:      "normal close is not an error",
+			closeCode: websocket.CloseNormalClosure,
+			wantLogs: []string{
+				`"msg"="WebSocket closed by client"`,
+				`"msg"="WebSocket connection closed"`,
+			},
+			wantNot: []string{`"msg"="WebSocket read error"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := &logCapture{}
+			logger := funcr.New(capture.write, funcr.Options{Verbosity: 1})
+			s := NewServer(&fakeProvider{}, "", 0, WithLogger(logger))
+
+			httpServer := httptest.NewServer(s.Handler())
+			defer httpServer.Close()
+
+			wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			if _, _, err := conn.ReadMessage(); err != nil {
+				t.Fatalf("ReadMessage() error = %v", err)
+			}
+			closeMessage := websocket.FormatCloseMessage(tt.closeCode, "bye")
+			if err := conn.WriteMessage(websocket.CloseMessage, closeMessage); err != nil {
+				t.Fatalf("WriteMessage() error = %v", err)
+			}
+			conn.Close()
+
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) && !capture.contains(`"msg"="WebSocket connection closed"`) {
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			for _, want := range tt.wantLogs {
+				if !capture.contains(want) {
+					t.Errorf("logs = %v, want entry containing %s", capture.lines, want)
+				}
+			}
+			for _, notWant := range tt.wantNot {
+				if capture.contains(notWant) {
+					t.Errorf("logs = %v, want no entry containing %s", capture.lines, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ConnectionsWebSocket(t *testing.T) {
+	provider := &fakeProvider{
+		updates: make(chan []*types.ServiceHealthInfo),
+		data: []*types.ServiceHealthInfo{
+			{ServiceName: "api", Namespace: "default"},
+		},
+	}
+	s := NewServer(provider, "", 0)
+
+	httpServer := httptest.NewServer(s.Handler())
+	defer httpServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws/connections"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	read := func() []types.Connection {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var connections []types.Connection
+		if err := conn.ReadJSON(&connections); err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+		return connections
+	}
+
+	want := []types.Connection{{Source: "Namespace/default", Target: "Service/default/api"}}
+	if got := read(); !slices.Equal(got, want) {
+		t.Errorf("initial connections = %v, want %v", got, want)
+	}
+
+	// A health-only change keeps the same edges and must not be sent
+	provider.updates <- []*types.ServiceHealthInfo{
+		{ServiceName: "api", Namespace: "default", Status: types.HealthStatusUnhealthy},
+	}
+	provider.updates <- []*types.ServiceHealthInfo{
+		{ServiceName: "api", Namespace: "default", Group: "backend"},
+	}
+
+	want = []types.Connection{
+		{Source: "Namespace/default", Target: "Namespace/default/Group/backend"},
+		{Source: "Namespace/default/Group/backend", Target: "Service/default/api"},
+	}
+	if got := read(); !slices.Equal(got, want) {
+		t.Errorf("updated connections = %v, want %v", got, want)
+	}
+}
+
+func TestServer_HierarchyWebSocket(t *testing.T) {
+	provider := &fakeProvider{
+		updates: make(chan []*types.ServiceHealthInfo),
+		data: []*types.ServiceHealthInfo{
+			{ServiceName: "api", Namespace: "default"},
+		},
+	}
+	s := NewServer(provider, "", 0)
+
+	httpServer := httptest.NewServer(s.Handler())
+	defer httpServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws/hierarchy"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	read := func() hierarchyMessage {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var message hierarchyMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+		return message
+	}
+
+	snapshot := read()
+	if snapshot.Type != hierarchyMessageSnapshot || snapshot.Seq != 0 {
+		t.Fatalf("first message = %v seq %v, want %v seq 0", snapshot.Type, snapshot.Seq, hierarchyMessageSnapshot)
+	}
+	tree := snapshot.Nodes
+
+	updates := [][]*types.ServiceHealthInfo{
+		{
+			{ServiceName: "api", Namespace: "default", Status: types.HealthStatusUnhealthy},
+			{ServiceName: "web", Namespace: "default", Group: "frontend"},
+		},
+		// Unchanged data produces no ops and must not be sent
+		{
+			{ServiceName: "api", Namespace: "default", Status: types.HealthStatusUnhealthy},
+			{ServiceName: "web", Namespace: "default", Group: "frontend"},
+		},
+		{
+			{ServiceName: "web", Namespace: "default", Group: "frontend"},
+			{ServiceName: "db", Namespace: "data"},
+		},
+	}
+	wantSeq := []uint64{1, 2}
+	received := 0
+	for i, data := range updates {
+		provider.updates <- data
+		if i == 1 {
+			continue
+		}
+
+		message := read()
+		if message.Type != hierarchyMessageOps || message.Seq != wantSeq[received] {
+			t.Errorf("update message = %v seq %v, want %v seq %v", message.Type, message.Seq, hierarchyMessageOps, wantSeq[received])
+		}
+		received++
+		tree = hierarchy.Apply(tree, message.Ops)
+
+		// Both sides went through JSON so they compare like for like
+		want := roundTrip(t, hierarchy.BuildGrouped(data))
+		if got := roundTrip(t, tree); !reflect.DeepEqual(got, want) {
+			t.Errorf("tree after seq %v = %+v, want %+v", message.Seq, got, want)
+		}
+	}
+}
+
+func roundTrip(t *testing.T, nodes []types.HierarchyNode) []types.HierarchyNode {
+	t.Helper()
+	encoded, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded []types.HierarchyNode
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return decoded
+}
+
+func TestWithWebSocketTimeouts(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []ServerOpt
+		wantWriteWait  time.Duration
+		wantPongWait   time.Duration
+		wantPingPeriod time.Duration
+		wantReadLimit  int64
+	}{
+		{
+			name:           "defaults",
+			wantWriteWait:  defaultWriteWait,
+			wantPongWait:   defaultPongWait,
+			wantPingPeriod: 54 * time.Second,
+			wantReadLimit:  defaultMaxMessageSize,
+		},
+		{
+			name: "custom values",
+			opts: []ServerOpt{
+				WithWebSocketTimeouts(time.Second, 20*time.Second, 5*time.Second),
+				WithWebSocketReadLimit(4096),
+			},
+			wantWriteWait:  time.Second,
+			wantPongWait:   20 * time.Second,
+			wantPingPeriod: 5 * time.Second,
+			wantReadLimit:  4096,
+		},
+		{
+			name:           "ping period not shorter than pong wait falls back",
+			opts:           []ServerOpt{WithWebSocketTimeouts(0, 10*time.Second, 10*time.Second)},
+			wantWriteWait:  defaultWriteWait,
+			wantPongWait:   10 * time.Second,
+			wantPingPeriod: 9 * time.Second,
+			wantReadLimit:  defaultMaxMessageSize,
+		},
+		{
+			name:           "shorter pong wait rederives default ping period",
+			opts:           []ServerOpt{WithWebSocketTimeouts(0, 30*time.Second, 0)},
+			wantWriteWait:  defaultWriteWait,
+			wantPongWait:   30 * time.Second,
+			wantPingPeriod: 27 * time.Second,
+			wantReadLimit:  defaultMaxMessageSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, "", 0, tt.opts...)
+			if s.writeWait != tt.wantWriteWait {
+				t.Errorf("writeWait = %v, want %v", s.writeWait, tt.wantWriteWait)
+			}
+			if s.pongWait != tt.wantPongWait {
+				t.Errorf("pongWait = %v, want %v", s.pongWait, tt.wantPongWait)
+			}
+			if s.pingPeriod != tt.wantPingPeriod {
+				t.Errorf("pingPeriod = %v, want %v", s.pingPeriod, tt.wantPingPeriod)
+			}
+			if s.maxMessageSize != tt.wantReadLimit {
+				t.Errorf("maxMessageSize = %v, want %v", s.maxMessageSize, tt.wantReadLimit)
+			}
+		})
+	}
+}
+
+func TestServer_WebSocketTimeoutsApplied(t *testing.T) {
+	s := NewServer(&fakeProvider{}, "", 0,
+		WithWebSocketTimeouts(time.Second, time.Second, 20*time.Millisecond),
+		WithWebSocketReadLimit(8),
+	)
+
+	httpServer := httptest.NewServer(s.Handler())
+	defer httpServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatalf("no ping received within the configured ping period")
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("longer than eight bytes")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	select {
+	case err := <-readErr:
+		if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+			t.Errorf("ReadMessage() error = %v, want close %d", err, websocket.CloseMessageTooBig)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("connection not closed after exceeding the read limit")
+	}
+}
+
+func TestServer_HandleGroupHealth(t *testing.T) {
+	payments := types.GroupHealth{
+		Group:         "payments",
+		Status:        types.HealthStatusUnhealthy,
+		HealthSummary: types.HealthSummary{Total: 2, Healthy: 1, Unhealthy: 1, HealthyPercent: 50, AverageUptime: 75},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "known group",
+			path:       "/groups/payments/health",
+			wantStatus: http.StatusOK,
+			wantBody: `{"group":"payments","status":"unhealthy","total":2,"healthy":1,"unhealthy":1,` +
+				`"unknown":0,"maintenance":0,"healthy_percent":50,"average_uptime":75}`,
+		},
+		{
+			name:       "unknown group",
+			path:       "/groups/search/health",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{groups: map[string]types.GroupHealth{"payments": payments}}
+			s := NewServer(provider, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("GET %s body = %s, want %s", tt.path, rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_ErrorResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		adminToken string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "invalid offset",
+			method:     http.MethodGet,
+			path:       "/state?offset=-1",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid offset: must not be negative, got -1",
+		},
+		{
+			name:       "unsupported groupBy",
+			method:     http.MethodGet,
+			path:       "/state?groupBy=label",
+			wantStatus: http.StatusBadRequest,
+			wantError:  `unsupported groupBy "label", must be annotation`,
+		},
+		{
+			name:       "unknown group",
+			method:     http.MethodGet,
+			path:       "/groups/search/health",
+			wantStatus: http.StatusNotFound,
+			wantError:  `group "search" not found`,
+		},
+		{
+			name:       "admin disabled",
+			method:     http.MethodPatch,
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"10s"}`,
+			wantStatus: http.StatusForbidden,
+			wantError:  "admin API is disabled",
+		},
+		{
+			name:       "unauthorized",
+			method:     http.MethodPatch,
+			path:       "/healthchecks/default/api",
+			body:       `{"interval":"10s"}`,
+			adminToken: "secret",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "unauthorized",
+		},
+		{
+			name:       "invalid since",
+			method:     http.MethodGet,
+			path:       "/events/log?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid since: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			name:       "unknown api path",
+			method:     http.MethodGet,
+			path:       "/state/missing",
+			wantStatus: http.StatusNotFound,
+			wantError:  "not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, t.TempDir(), 0, WithAdminToken(tt.adminToken))
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %v, want %v", tt.method, tt.path, rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("%s %s Content-Type = %v, want application/json", tt.method, tt.path, got)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("%s %s body %q is not JSON: %v", tt.method, tt.path, rec.Body.String(), err)
+			}
+			if body["error"] != tt.wantError {
+				t.Errorf("%s %s error = %v, want %v", tt.method, tt.path, body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+func TestServer_HandleRunCheck(t *testing.T) {
+	entries := []types.HealthCheckEntry{{Status: types.HealthStatusHealthy, ResponseCode: http.StatusOK}}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		authHeader string
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name:       "missing bearer token",
+			method:     http.MethodPost,
+			path:       "/health/default/api/check",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "registered target",
+			method:     http.MethodPost,
+			path:       "/health/default/api/check",
+			authHeader: "Bearer secret",
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "unknown target",
+			method:     http.MethodPost,
+			path:       "/health/default/missing/check",
+			authHeader: "Bearer secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rate limited target",
+			method:     http.MethodPost,
+			path:       "/health/default/busy/check",
+			authHeader: "Bearer secret",
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       "/health/default/api/check",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{
+				targets: map[string]bool{"default/api": true, "default/busy": true},
+				limited: map[string]bool{"default/busy": true},
+				entries: entries,
+			}
+			s := NewServer(provider, "", 0, WithAdminToken("secret"))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %v, want %v", tt.method, tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []types.HealthCheckEntry
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("%s %s entries = %v, want %v", tt.method, tt.path, len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestServer_HandleRunNamespaceChecks(t *testing.T) {
+	entries := []types.HealthCheckEntry{{Status: types.HealthStatusHealthy, ResponseCode: http.StatusOK}}
+
+	tests := []struct {
+		name            string
+		path            string
+		authHeader      string
+		wantStatus      int
+		wantServices    []string
+		wantRateLimited []string
+	}{
+		{
+			name:       "missing bearer token",
+			path:       "/health/default/check",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:            "namespace with targets",
+			path:            "/health/default/check",
+			authHeader:      "Bearer secret",
+			wantStatus:      http.StatusOK,
+			wantServices:    []string{"api", "web"},
+			wantRateLimited: []string{"worker"},
+		},
+		{
+			name:       "namespace without targets",
+			path:       "/health/missing/check",
+			authHeader: "Bearer secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "every target rate limited",
+			path:       "/health/busy/check",
+			authHeader: "Bearer secret",
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{
+				targets: map[string]bool{
+					"default/api": true, "default/web": true, "default/worker": true, "other/db": true, "busy/queue": true,
+				},
+				limited: map[string]bool{"default/worker": true, "busy/queue": true},
+				entries: entries,
+			}
+			s := NewServer(provider, "", 0, WithAdminToken("secret"))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("POST %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got types.NamespaceChecks
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			services := make([]string, 0, len(got.Services))
+			for service := range got.Services {
+				services = append(services, service)
+			}
+			slices.Sort(services)
+			if !slices.Equal(services, tt.wantServices) {
+				t.Errorf("POST %s services = %v, want %v", tt.path, services, tt.wantServices)
+			}
+			if !slices.Equal(got.RateLimited, tt.wantRateLimited) {
+				t.Errorf("POST %s rate limited = %v, want %v", tt.path, got.RateLimited, tt.wantRateLimited)
+			}
+		})
+	}
+}
+
+func TestServer_HandleState(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "a", Namespace: "default"},
+		{ServiceName: "b", Namespace: "default"},
+		{ServiceName: "c", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPage   *types.HealthDataPage
+		wantCount  int
+	}{
+		{
+			name:       "no params returns a plain array",
+			wantStatus: http.StatusOK,
+			wantCount:  3,
+		},
+		{
+			name:       "offset and limit",
+			query:      "?offset=1&limit=1",
+			wantStatus: http.StatusOK,
+			wantPage:   &types.HealthDataPage{Items: data[1:2], Total: 3, Offset: 1, Limit: 1},
+		},
+		{
+			name:       "limit past the end",
+			query:      "?offset=2&limit=10",
+			wantStatus: http.StatusOK,
+			wantPage:   &types.HealthDataPage{Items: data[2:], Total: 3, Offset: 2, Limit: 10},
+		},
+		{
+			name:       "offset past the end",
+			query:      "?offset=3",
+			wantStatus: http.StatusOK,
+			wantPage:   &types.HealthDataPage{Items: []*types.ServiceHealthInfo{}, Total: 3, Offset: 3},
+		},
+		{
+			name:       "negative limit",
+			query:      "?limit=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "non-numeric offset",
+			query:      "?offset=first",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{data: data}, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET /state%s status = %v, want %v", tt.query, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if tt.wantPage == nil {
+				var got []*types.ServiceHealthInfo
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if len(got) != tt.wantCount {
+					t.Errorf("GET /state%s items = %v, want %v", tt.query, len(got), tt.wantCount)
+				}
+				return
+			}
+
+			var got types.HealthDataPage
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, *tt.wantPage) {
+				t.Errorf("GET /state%s = %+v, want %+v", tt.query, got, *tt.wantPage)
+			}
+		})
+	}
+}
+
+func TestServer_HandleStateStatusFilter(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "a", Namespace: "default", Status: types.HealthStatusHealthy},
+		{ServiceName: "b", Namespace: "default", Status: types.HealthStatusUnhealthy},
+		{ServiceName: "c", Namespace: "default", Status: types.HealthStatusUnknown},
+		{ServiceName: "d", Namespace: "default", Status: types.HealthStatusUnhealthy},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+		wantTotal  int
+	}{
+		{
+			name:       "single status",
+			query:      "?status=unhealthy",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"b", "d"},
+		},
+		{
+			name:       "multiple statuses",
+			query:      "?status=healthy,unknown",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"a", "c"},
+		},
+		{
+			name:       "no match",
+			query:      "?status=maintenance",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{},
+		},
+		{
+			name:       "paged after filtering",
+			query:      "?status=unhealthy&offset=1&limit=1",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"d"},
+			wantTotal:  2,
+		},
+		{
+			name:       "invalid status",
+			query:      "?status=degraded",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "one invalid among valid",
+			query:      "?status=healthy,broken",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{data: data}, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET /state%s status = %v, want %v", tt.query, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var items []*types.ServiceHealthInfo
+			if tt.wantTotal > 0 {
+				var page types.HealthDataPage
+				if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if page.Total != tt.wantTotal {
+					t.Errorf("GET /state%s total = %v, want %v", tt.query, page.Total, tt.wantTotal)
+				}
+				items = page.Items
+			}
+			if tt.wantTotal == 0 {
+				if err := json.NewDecoder(rec.Body).Decode(&items); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+			}
+
+			names := make([]string, 0, len(items))
+			for _, item := range items {
+				names = append(names, item.ServiceName)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("GET /state%s services = %v, want %v", tt.query, names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestServer_HandleStateSort(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "a", Namespace: "default", Status: types.HealthStatusHealthy, Uptime: 99},
+		{ServiceName: "b", Namespace: "default", Status: types.HealthStatusUnhealthy, Uptime: 40},
+		{ServiceName: "c", Namespace: "default", Status: types.HealthStatusUnhealthy, Uptime: 75},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		{name: "default key order", query: "", wantStatus: http.StatusOK, wantNames: []string{"a", "b", "c"}},
+		{name: "uptime", query: "?sort=uptime", wantStatus: http.StatusOK, wantNames: []string{"b", "c", "a"}},
+		{name: "filtered and sorted", query: "?sort=uptime&status=unhealthy", wantStatus: http.StatusOK, wantNames: []string{"b", "c"}},
+		{name: "paged after sorting", query: "?sort=uptime&limit=1", wantStatus: http.StatusOK, wantNames: []string{"b"}},
+		{name: "invalid sort", query: "?sort=name", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{data: data}, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET /state%s status = %v, want %v", tt.query, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var items []*types.ServiceHealthInfo
+			if strings.Contains(tt.query, "limit") {
+				var page types.HealthDataPage
+				if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				items = page.Items
+			}
+			if !strings.Contains(tt.query, "limit") {
+				if err := json.NewDecoder(rec.Body).Decode(&items); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+			}
+
+			names := make([]string, 0, len(items))
+			for _, item := range items {
+				names = append(names, item.ServiceName)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("GET /state%s services = %v, want %v", tt.query, names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestServer_HandleProbes(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		cacheSynced bool
+		running     bool
+		wantStatus  int
+	}{
+		{name: "livez before ready", path: "/livez", wantStatus: http.StatusOK},
+		{name: "livez when ready", path: "/livez", cacheSynced: true, running: true, wantStatus: http.StatusOK},
+		{name: "readyz before cache sync", path: "/readyz", running: true, wantStatus: http.StatusServiceUnavailable},
+		{name: "readyz before checker runs", path: "/readyz", cacheSynced: true, wantStatus: http.StatusServiceUnavailable},
+		{name: "readyz when ready", path: "/readyz", cacheSynced: true, running: true, wantStatus: http.StatusOK},
+		{name: "healthz before ready", path: "/healthz", wantStatus: http.StatusServiceUnavailable},
+		{name: "healthz when ready with no services", path: "/healthz", cacheSynced: true, running: true,
+			wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, "", 0,
+				WithReadiness("cache-synced", func() bool { return tt.cacheSynced }),
+				WithReadiness("health-checker", func() bool { return tt.running }),
+			)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServer_HandleReadyReportsWaiting(t *testing.T) {
+	var ready atomic.Bool
+	s := NewServer(&fakeProvider{}, "", 0,
+		WithReadiness("cache-synced", ready.Load),
+		WithReadiness("health-checker", func() bool { return true }),
+	)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body struct {
+		Waiting []string `json:"waiting"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding /readyz: %v", err)
+	}
+	if len(body.Waiting) != 1 || body.Waiting[0] != "cache-synced" {
+		t.Errorf("GET /readyz waiting = %v, want [cache-synced]", body.Waiting)
+	}
+
+	ready.Store(true)
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /readyz after ready status = %v, want %v", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServer_HandleStateGroupBy(t *testing.T) {
+	data := []*types.ServiceHealthInfo{
+		{ServiceName: "api", Namespace: "default", Group: "platform/backend"},
+		{ServiceName: "web", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "annotation", query: "?groupBy=annotation", wantStatus: http.StatusOK},
+		{name: "unsupported", query: "?groupBy=label", wantStatus: http.StatusBadRequest},
+		{name: "empty sort", query: "?groupBy=annotation&sort=", wantStatus: http.StatusOK},
+		{name: "combined with sort", query: "?groupBy=annotation&sort=uptime", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{data: data}, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET /state%s status = %v, want %v", tt.query, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var nodes []types.HierarchyNode
+			if err := json.NewDecoder(rec.Body).Decode(&nodes); err != nil {
+				t.Fatalf("decoding /state%s: %v", tt.query, err)
+			}
+			if len(nodes) != 1 || len(nodes[0].Relatives) != 2 {
+				t.Fatalf("GET /state%s = %+v, want one namespace with a group and a service", tt.query, nodes)
+			}
+			group := nodes[0].Relatives[0]
+			if group.Kind != types.ResourceKindGroup || group.Name != "platform" {
+				t.Errorf("GET /state%s first relative = %v/%v, want Group/platform", tt.query, group.Kind, group.Name)
+			}
+			if len(group.Relatives) != 1 || group.Relatives[0].Name != "backend" {
+				t.Errorf("GET /state%s platform relatives = %+v, want the backend group", tt.query, group.Relatives)
+			}
+		})
+	}
+}
+
+func TestServer_HandleClusterState(t *testing.T) {
+	s := NewServer(&fakeProvider{data: []*types.ServiceHealthInfo{
+		{ServiceName: "api", Namespace: "default", Group: "backend"},
+	}}, "", 0)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cluster-state", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /cluster-state status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	var state types.ClusterState
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatalf("decoding /cluster-state: %v", err)
+	}
+	for _, id := range []string{"Namespace/default", "Namespace/default/Group/backend", "Service/default/api"} {
+		if _, ok := state.Resources[id]; !ok {
+			t.Errorf("GET /cluster-state resources = %v, want %s", state.Resources, id)
+		}
+	}
+	if len(state.Connections) != 2 {
+		t.Errorf("GET /cluster-state connections = %v, want 2", state.Connections)
+	}
+}
+
+func TestServer_HandleSchema(t *testing.T) {
+	s := NewServer(&fakeProvider{}, "", 0)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schema", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /schema status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/schema+json" {
+		t.Errorf("GET /schema Content-Type = %v, want application/schema+json", got)
+	}
+
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&schema); err != nil {
+		t.Fatalf("decoding /schema: %v", err)
+	}
+	for def, property := range map[string]string{
+		"HierarchyNode":     "relatives",
+		"ServiceHealthInfo": "last_transition_time",
+		"HealthCheckEntry":  "response_code",
+	} {
+		if _, ok := schema.Defs[def].Properties[property]; !ok {
+			t.Errorf("GET /schema %s properties = %v, want %s", def, schema.Defs[def].Properties, property)
+		}
+	}
+}
+
+func TestServer_HandleEventLog(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []types.StateEvent{
+		{Kind: types.ResourceKindService, Name: "api", Namespace: "default", Type: types.StateEventAdd, Timestamp: base},
+		{Kind: types.ResourceKindService, Name: "api", Namespace: "default", Type: types.StateEventDelete,
+			Timestamp: base.Add(time.Minute)},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{name: "all events", wantStatus: http.StatusOK, wantCount: 2},
+		{name: "since filter", query: "?since=2025-01-01T12:00:30Z", wantStatus: http.StatusOK, wantCount: 1},
+		{name: "invalid since", query: "?since=yesterday", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{events: events}, "", 0)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/log"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET /events/log%s status = %v, want %v", tt.query, rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []types.StateEvent
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("GET /events/log%s events = %v, want %v", tt.query, len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestServer_HandleTargets(t *testing.T) {
+	hc := healthcheck.NewHealthChecker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = hc.Start(ctx) }()
+
+	api := []healthcheck.CheckConfig{{
+		Name: "default/api", URL: "http://api.default.svc.cluster.local/healthz",
+		Interval: time.Hour, Timeout: time.Second, Protocol: "http",
+	}}
+	db := []healthcheck.CheckConfig{{
+		Name: "data/db", URL: "tcp://db.data.svc.cluster.local:5432",
+		Interval: 30 * time.Minute, Timeout: 2 * time.Second, Protocol: "tcp",
+	}}
+	hc.RegisterHealthTarget("default/api", api)
+	hc.RegisterHealthTarget("data/db", db)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(hc.GetTargets()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	s := NewServer(hc, "", 0)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /targets status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	var got []healthcheck.HealthTarget
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := []healthcheck.HealthTarget{
+		{Name: "data/db", Checks: db},
+		{Name: "default/api", Checks: api},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GET /targets = %+v, want %+v", got, want)
+	}
+}
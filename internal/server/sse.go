@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseHeartbeat is how often a comment is sent to keep idle proxies from closing the stream
+const sseHeartbeat = 15 * time.Second
+
+// handleEvents streams health data as server-sent events, for clients and
+// proxies that handle SSE more reliably than WebSockets
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	healthChan := s.healthProvider.Subscribe()
+	defer s.healthProvider.Unsubscribe(healthChan)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	logger := s.logger.WithValues("remote", r.RemoteAddr)
+	logger.V(1).Info("SSE stream opened")
+	defer logger.V(1).Info("SSE stream closed")
+
+	if err := writeEvent(w, s.healthProvider.GetAllHealthData()); err != nil {
+		logger.Error(err, "SSE initial write error")
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case data := <-healthChan:
+			if err := writeEvent(w, data); err != nil {
+				logger.Error(err, "SSE write error")
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				logger.Error(err, "SSE heartbeat error")
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// writeEvent writes data as a single SSE frame. JSON output never contains a
+// raw newline, so one data line is enough.
+func writeEvent(w http.ResponseWriter, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", payload)
+	return err
+}
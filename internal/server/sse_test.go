@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+func TestServer_HandleEvents(t *testing.T) {
+	provider := &fakeProvider{updates: make(chan []*types.ServiceHealthInfo, 1)}
+	server := httptest.NewServer(NewServer(provider, "", 0).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("GET /events content type = %v, want text/event-stream", got)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readFrame := func() string {
+		t.Helper()
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString() error = %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if got, want := readFrame(), "data: null"; got != want {
+		t.Errorf("initial frame = %q, want %q", got, want)
+	}
+
+	provider.updates <- []*types.ServiceHealthInfo{{ServiceName: "api", Namespace: "default", Status: "healthy"}}
+	got := readFrame()
+	if !strings.HasPrefix(got, "data: ") || !strings.Contains(got, `"service_name":"api"`) {
+		t.Errorf("update frame = %q, want data frame for api", got)
+	}
+}
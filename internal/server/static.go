@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// defaultAssetMaxAge is how long browsers may cache content-hashed assets
+const defaultAssetMaxAge = 365 * 24 * time.Hour
+
+// assetPrefix is where the frontend build puts its content-hashed files
+const assetPrefix = "/assets/"
+
+// apiPrefixes are the roots of the API routes. Unknown paths under them are
+// 404s rather than SPA deep links.
+var apiPrefixes = []string{
+	"/state", "/cluster-state", "/schema", "/version", "/summary", "/groups", "/healthmetrics", "/targets", "/ws", "/events",
+	"/healthz", "/readyz", "/livez", "/healthchecks", "/health",
+}
+
+// WithAssetMaxAge sets how long browsers may cache content-hashed assets.
+// Zero sends no-cache for them like every other file.
+func WithAssetMaxAge(maxAge time.Duration) ServerOpt {
+	return func(s *Server) {
+		s.assetMaxAge = maxAge
+	}
+}
+
+// staticFileHandler serves the UI. Paths that are not files fall back to
+// index.html so deep links into the SPA load, except under API routes.
+func (s *Server) staticFileHandler(fileServer http.Handler) http.HandlerFunc {
+	root := http.Dir(s.staticDir)
+	return func(w http.ResponseWriter, r *http.Request) {
+		upath := path.Clean("/" + r.URL.Path)
+		if isAPIPath(upath) {
+			writeJSONError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		// Only assets that exist are immutable, so a 404 for one is not cached
+		// across the deploy that adds it
+		exists := fileExists(root, upath)
+		if exists && strings.HasPrefix(upath, assetPrefix) && s.assetMaxAge > 0 {
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(s.assetMaxAge.Seconds())))
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		if upath == "/" || exists || strings.HasPrefix(upath, assetPrefix) {
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		serveIndex(w, r, root)
+	}
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request, root http.Dir) {
+	f, err := root.Open("/index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, "index.html", info.ModTime(), f)
+}
+
+func isAPIPath(upath string) bool {
+	for _, prefix := range apiPrefixes {
+		if upath == prefix || strings.HasPrefix(upath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(root http.Dir, upath string) bool {
+	f, err := root.Open(upath)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	_, err = f.Stat()
+	return err == nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer_StaticFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	files := map[string]string{
+		"index.html":            "<html>app</html>",
+		"favicon.ico":           "icon",
+		"assets/index-3f2a1.js": "console.log('app')",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		opts             []ServerOpt
+		wantStatus       int
+		wantBody         string
+		wantCacheControl string
+	}{
+		{
+			name:             "root serves index without caching",
+			path:             "/",
+			wantStatus:       http.StatusOK,
+			wantBody:         "<html>app</html>",
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:             "deep link falls back to index",
+			path:             "/namespace/foo",
+			wantStatus:       http.StatusOK,
+			wantBody:         "<html>app</html>",
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:             "hashed asset is cached",
+			path:             "/assets/index-3f2a1.js",
+			wantStatus:       http.StatusOK,
+			wantBody:         "console.log('app')",
+			wantCacheControl: "public, max-age=31536000, immutable",
+		},
+		{
+			name:             "asset caching disabled",
+			path:             "/assets/index-3f2a1.js",
+			opts:             []ServerOpt{WithAssetMaxAge(0)},
+			wantStatus:       http.StatusOK,
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:       "missing asset is not served index",
+			path:       "/assets/missing.js",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:             "other file is revalidated",
+			path:             "/favicon.ico",
+			wantStatus:       http.StatusOK,
+			wantBody:         "icon",
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:       "missing api route is a 404",
+			path:       "/state/missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing route under health is a 404",
+			path:       "/health/default/api",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "deep link only falls back for reads",
+			method:     http.MethodPost,
+			path:       "/namespace/foo",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, dir, 0, tt.opts...)
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(method, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %v, want %v", method, tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s body = %q, want %q", method, tt.path, rec.Body.String(), tt.wantBody)
+			}
+			if tt.wantCacheControl != "" && rec.Header().Get("Cache-Control") != tt.wantCacheControl {
+				t.Errorf("%s %s Cache-Control = %q, want %q", method, tt.path,
+					rec.Header().Get("Cache-Control"), tt.wantCacheControl)
+			}
+		})
+	}
+}
+
+func TestServer_StaticMissingAssetNotImmutable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "index-3f2a1.js"), []byte("app"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "existing asset", path: "/assets/index-3f2a1.js", want: "public, max-age=31536000, immutable"},
+		{name: "missing asset", path: "/assets/index-9c8b7.js", want: "no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeProvider{}, dir, 0)
+			// Unlike http.FileServer, this file server keeps the headers it is
+			// handed on a 404, so the handler's choice is what gets sent
+			handler := s.staticFileHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("GET %s Cache-Control = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
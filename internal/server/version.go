@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// VersionInfo identifies the running controller build and frontend bundle
+type VersionInfo struct {
+	Version    string `json:"version"`
+	BundleHash string `json:"bundle_hash,omitempty"`
+}
+
+// WithVersion sets the controller build version reported by /version
+func WithVersion(version string) ServerOpt {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// Rehash recomputes the frontend bundle hash from index.html, which references
+// every content-hashed asset, so it changes whenever the bundle is rebuilt. The
+// previous hash is kept when index.html cannot be read.
+func (s *Server) Rehash() error {
+	if s.staticDir == "" {
+		return nil
+	}
+
+	index, err := os.ReadFile(filepath.Join(s.staticDir, "index.html"))
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(index)
+	s.bundleHash.Store(hex.EncodeToString(sum[:]))
+	return nil
+}
+
+// reloadOnSignal rehashes the bundle whenever a signal arrives, so a bundle
+// updated in place can be confirmed without restarting
+func (s *Server) reloadOnSignal(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-signals:
+			if err := s.Rehash(); err != nil {
+				s.logger.Error(err, "failed to rehash frontend bundle")
+				continue
+			}
+			s.logger.Info("rehashed frontend bundle", "hash", s.bundleHash.Load())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	hash, _ := s.bundleHash.Load().(string)
+	writeJSON(w, http.StatusOK, VersionInfo{Version: s.version, BundleHash: hash})
+}
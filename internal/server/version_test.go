@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func hashOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestServer_HandleVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    string
+		noStatic bool
+		wantHash string
+	}{
+		{
+			name:     "hashes index.html",
+			index:    "<html>v1</html>",
+			wantHash: hashOf("<html>v1</html>"),
+		},
+		{
+			name:     "no static dir",
+			noStatic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			staticDir := ""
+			if !tt.noStatic {
+				staticDir = t.TempDir()
+				if err := os.WriteFile(filepath.Join(staticDir, "index.html"), []byte(tt.index), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s := NewServer(&fakeProvider{}, staticDir, 0, WithVersion("v1.2.3"))
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /version status = %v, want %v", rec.Code, http.StatusOK)
+			}
+			var got VersionInfo
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("GET /version body is not JSON: %v", err)
+			}
+			want := VersionInfo{Version: "v1.2.3", BundleHash: tt.wantHash}
+			if got != want {
+				t.Errorf("GET /version = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestServer_RehashOnSignal(t *testing.T) {
+	staticDir := t.TempDir()
+	index := filepath.Join(staticDir, "index.html")
+	if err := os.WriteFile(index, []byte("<html>v1</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(&fakeProvider{}, staticDir, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	go s.reloadOnSignal(ctx, signals)
+
+	if err := os.WriteFile(index, []byte("<html>v2</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.bundleHash.Load(); got != hashOf("<html>v1</html>") {
+		t.Errorf("bundle hash before signal = %v, want the startup hash", got)
+	}
+
+	signals <- syscall.SIGHUP
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && s.bundleHash.Load() != hashOf("<html>v2</html>") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := s.bundleHash.Load(); got != hashOf("<html>v2</html>") {
+		t.Errorf("bundle hash after signal = %v, want %v", got, hashOf("<html>v2</html>"))
+	}
+
+	if err := os.Remove(index); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rehash(); err == nil {
+		t.Errorf("Rehash() without index.html error = nil, want error")
+	}
+	if got := s.bundleHash.Load(); got != hashOf("<html>v2</html>") {
+		t.Errorf("bundle hash after failed rehash = %v, want the previous hash", got)
+	}
+}
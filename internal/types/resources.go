@@ -15,6 +15,7 @@ const (
 	ResourceKindService   ResourceKind = "Service"
 	ResourceKindPod       ResourceKind = "Pod"
 	ResourceKindHTTPRoute ResourceKind = "HTTPRoute"
+	ResourceKindGroup     ResourceKind = "Group"
 )
 
 func (r ResourceKind) String() string {
@@ -38,6 +39,7 @@ type ResourceMetadata struct {
 	Phase           *string             `json:"phase,omitempty"`
 	BackendRefs     []string            `json:"backend_refs,omitempty"`
 	ServiceType     *string             `json:"service_type,omitempty"`
+	ExternalName    string              `json:"external_name,omitempty"`
 	ClusterIPs      []string            `json:"cluster_ips,omitempty"`
 	ExternalIPs     []string            `json:"external_ips,omitempty"`
 	PodIPs          []string            `json:"pod_ips,omitempty"`
@@ -72,6 +74,7 @@ type HierarchyNode struct {
 	Phase           *string             `json:"phase,omitempty"`
 	BackendRefs     []string            `json:"backend_refs,omitempty"`
 	ServiceType     *string             `json:"service_type,omitempty"`
+	ExternalName    string              `json:"external_name,omitempty"`
 	ClusterIPs      []string            `json:"cluster_ips,omitempty"`
 	ExternalIPs     []string            `json:"external_ips,omitempty"`
 	PodIPs          []string            `json:"pod_ips,omitempty"`
@@ -125,24 +128,107 @@ const (
 	HealthStatusHealthy   HealthStatus = "healthy"
 	HealthStatusUnhealthy HealthStatus = "unhealthy"
 	HealthStatusUnknown   HealthStatus = "unknown"
+	// HealthStatusMaintenance replaces unhealthy while a service is under planned maintenance
+	HealthStatusMaintenance HealthStatus = "maintenance"
 )
 
+// HealthCheckEntry is a single check result. URL and Method are only set when
+// they differ from the owning ServiceHealthInfo, so the common case of one
+// check per service does not repeat them across the whole history.
 type HealthCheckEntry struct {
 	Timestamp    time.Time     `json:"timestamp"`
 	Status       HealthStatus  `json:"status"`
 	Latency      time.Duration `json:"latency"`
 	Error        string        `json:"error,omitempty"`
-	URL          string        `json:"url"`
-	Method       string        `json:"method"`
+	URL          string        `json:"url,omitempty"`
+	Method       string        `json:"method,omitempty"`
 	ResponseCode int           `json:"response_code,omitempty"`
 }
 
+// CheckHealth is the latest result of one check of a service. The service
+// status is aggregated from the statuses of all of its checks. Like the URL of
+// a HealthCheckEntry, URL is only set when it differs from the owning
+// ServiceHealthInfo.
+type CheckHealth struct {
+	Name      string        `json:"name"`
+	URL       string        `json:"url,omitempty"`
+	Status    HealthStatus  `json:"status"`
+	LastCheck time.Time     `json:"last_check"`
+	Latency   time.Duration `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type StateEventType string
+
+const (
+	StateEventAdd    StateEventType = "add"
+	StateEventUpdate StateEventType = "update"
+	StateEventDelete StateEventType = "delete"
+)
+
+// StateEvent records a change to the set of monitored resources
+type StateEvent struct {
+	Kind      ResourceKind   `json:"kind"`
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Type      StateEventType `json:"type"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
 type ServiceHealthInfo struct {
-	ServiceName string             `json:"service_name"`
-	Namespace   string             `json:"namespace"`
-	LastCheck   time.Time          `json:"last_check"`
-	Status      HealthStatus       `json:"status"`
-	Uptime      float64            `json:"uptime"`
-	History     []HealthCheckEntry `json:"history"`
-	URL         string             `json:"url"`
+	ServiceName        string             `json:"service_name"`
+	Namespace          string             `json:"namespace"`
+	LastCheck          time.Time          `json:"last_check"`
+	Status             HealthStatus       `json:"status"`
+	LastTransitionTime time.Time          `json:"last_transition_time"`
+	Uptime             float64            `json:"uptime"`
+	AvgLatency         time.Duration      `json:"avg_latency"`
+	P50Latency         time.Duration      `json:"p50_latency"`
+	P95Latency         time.Duration      `json:"p95_latency"`
+	P99Latency         time.Duration      `json:"p99_latency"`
+	CurrentStreak      int                `json:"current_streak"`
+	StreakStatus       HealthStatus       `json:"streak_status,omitempty"`
+	History            []HealthCheckEntry `json:"history"`
+	URL                string             `json:"url"`
+	Method             string             `json:"method"`
+	Group              string             `json:"group,omitempty"`
+	Warnings           []string           `json:"warnings,omitempty"`
+	ExternalName       string             `json:"external_name,omitempty"`
+	Checks             []CheckHealth      `json:"checks,omitempty"`
+}
+
+// HealthDataPage is a window of services ordered by namespace/name. Total is
+// the number of services across all pages.
+type HealthDataPage struct {
+	Items  []*ServiceHealthInfo `json:"items"`
+	Total  int                  `json:"total"`
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
+
+// HealthSummary aggregates health across all tracked services
+type HealthSummary struct {
+	Total          int     `json:"total"`
+	Healthy        int     `json:"healthy"`
+	Unhealthy      int     `json:"unhealthy"`
+	Unknown        int     `json:"unknown"`
+	Maintenance    int     `json:"maintenance"`
+	HealthyPercent float64 `json:"healthy_percent"`
+	AverageUptime  float64 `json:"average_uptime"`
+}
+
+// NamespaceChecks are the results of checking every target of a namespace at
+// once. RateLimited lists the services skipped for being checked manually too
+// often.
+type NamespaceChecks struct {
+	Services    map[string][]HealthCheckEntry `json:"services"`
+	RateLimited []string                      `json:"rate_limited,omitempty"`
+}
+
+// GroupHealth aggregates health across the services sharing a group. Status is
+// unhealthy if any member is unhealthy, otherwise unknown if any member is unknown.
+type GroupHealth struct {
+	Group  string       `json:"group"`
+	Status HealthStatus `json:"status"`
+	HealthSummary
 }
@@ -0,0 +1,36 @@
+package types_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kdwils/constellation/internal/types"
+)
+
+// TestStateTypesHaveJSONTags keeps the /schema contract explicit: every field
+// reachable from the /state documents must name its JSON key
+func TestStateTypesHaveJSONTags(t *testing.T) {
+	seen := map[reflect.Type]bool{}
+	var walk func(reflect.Type)
+	walk = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || typ.PkgPath() != reflect.TypeFor[types.HierarchyNode]().PkgPath() || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if _, ok := field.Tag.Lookup("json"); !ok {
+				t.Errorf("%s.%s has no json tag", typ.Name(), field.Name)
+			}
+			walk(field.Type)
+		}
+	}
+	walk(reflect.TypeFor[types.HierarchyNode]())
+	walk(reflect.TypeFor[types.ServiceHealthInfo]())
+}